@@ -2,8 +2,8 @@ package utils
 
 import (
 	"math"
-	"math/rand"
-	"time"
+	"reflect"
+	"strconv"
 )
 
 // ArrayFill — Fill an array with values
@@ -131,15 +131,31 @@ func ArraySlice(s []interface{}, offset, length uint) []interface{} {
 }
 
 // ArrayRand — Pick one or more random keys out of an array
-func ArrayRand(elements []interface{}) []interface{} {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	n := make([]interface{}, len(elements))
+// Returns num distinct indexes of elements in ascending order, drawn from the package random source.
+// num must be between 1 and the number of elements.
+func ArrayRand(elements []interface{}, num int) []int {
+	n := len(elements)
+	if n == 0 {
+		panic("elements: cannot be empty")
+	}
+	if num < 1 || num > n {
+		panic("num: must be between 1 and the number of elements")
+	}
 
-	for i, v := range r.Perm(len(elements)) {
-		n[i] = elements[v]
+	r := getRandom()
+	if num == 1 {
+		return []int{int(randRange(r, 0, int64(n-1)))}
 	}
 
-	return n
+	// selection sampling keeps the keys in their original order
+	keys := make([]int, 0, num)
+	for i := 0; i < n && len(keys) < num; i++ {
+		if randRange(r, 0, int64(n-i-1)) < int64(num-len(keys)) {
+			keys = append(keys, i)
+		}
+	}
+
+	return keys
 }
 
 // ArrayColumn — Return the values from a single column in the input array
@@ -220,3 +236,293 @@ func ArrayReverse(s []interface{}) []interface{} {
 
 	return s
 }
+
+// ArrayDiff — Computes the difference of arrays
+// Values are compared as strings, (string) $a === (string) $b, so 1, "1" and 1.0 are the same value.
+// Like PHP, the result keeps the original indexes of the remaining values.
+func ArrayDiff(s []interface{}, others ...[]interface{}) map[int]interface{} {
+	exclude := make(map[string]bool)
+	for _, o := range others {
+		for _, v := range o {
+			exclude[phpString(v)] = true
+		}
+	}
+
+	n := make(map[int]interface{})
+	for i, v := range s {
+		if !exclude[phpString(v)] {
+			n[i] = v
+		}
+	}
+
+	return n
+}
+
+// ArrayDiffKey — Computes the difference of arrays using keys for comparison
+func ArrayDiffKey(m map[interface{}]interface{}, others ...map[interface{}]interface{}) map[interface{}]interface{} {
+	n := make(map[interface{}]interface{})
+
+	for k, v := range m {
+		found := false
+		for _, o := range others {
+			if _, ok := o[k]; ok {
+				found = true
+				break
+			}
+		}
+		if !found {
+			n[k] = v
+		}
+	}
+
+	return n
+}
+
+// ArrayDiffAssoc — Computes the difference of arrays with additional index check
+// An entry is removed when another array has the same key with a value that is equal as a string.
+func ArrayDiffAssoc(m map[interface{}]interface{}, others ...map[interface{}]interface{}) map[interface{}]interface{} {
+	n := make(map[interface{}]interface{})
+
+	for k, v := range m {
+		found := false
+		for _, o := range others {
+			if ov, ok := o[k]; ok && phpString(ov) == phpString(v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			n[k] = v
+		}
+	}
+
+	return n
+}
+
+// ArrayIntersect — Computes the intersection of arrays
+// Values are compared as strings and the result keeps the original indexes of the remaining values.
+func ArrayIntersect(s []interface{}, others ...[]interface{}) map[int]interface{} {
+	sets := make([]map[string]bool, len(others))
+	for i, o := range others {
+		sets[i] = make(map[string]bool, len(o))
+		for _, v := range o {
+			sets[i][phpString(v)] = true
+		}
+	}
+
+	n := make(map[int]interface{})
+	for i, v := range s {
+		str, ok := phpString(v), true
+		for _, set := range sets {
+			if !set[str] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			n[i] = v
+		}
+	}
+
+	return n
+}
+
+// ArrayIntersectKey — Computes the intersection of arrays using keys for comparison
+func ArrayIntersectKey(m map[interface{}]interface{}, others ...map[interface{}]interface{}) map[interface{}]interface{} {
+	n := make(map[interface{}]interface{})
+
+	for k, v := range m {
+		ok := true
+		for _, o := range others {
+			if _, ok = o[k]; !ok {
+				break
+			}
+		}
+		if ok {
+			n[k] = v
+		}
+	}
+
+	return n
+}
+
+// ArrayIntersectAssoc — Computes the intersection of arrays with additional index check
+func ArrayIntersectAssoc(m map[interface{}]interface{}, others ...map[interface{}]interface{}) map[interface{}]interface{} {
+	n := make(map[interface{}]interface{})
+
+	for k, v := range m {
+		ok := true
+		for _, o := range others {
+			ov, exists := o[k]
+			if ok = exists && phpString(ov) == phpString(v); !ok {
+				break
+			}
+		}
+		if ok {
+			n[k] = v
+		}
+	}
+
+	return n
+}
+
+// ArrayUnique — Removes duplicate values from an array
+// Values are compared as strings and the first occurrence is kept with its original index.
+func ArrayUnique(s []interface{}) map[int]interface{} {
+	seen := make(map[string]bool, len(s))
+	n := make(map[int]interface{})
+
+	for i, v := range s {
+		str := phpString(v)
+		if !seen[str] {
+			seen[str] = true
+			n[i] = v
+		}
+	}
+
+	return n
+}
+
+// ArraySearch — Searches the array for a given value and returns the first corresponding key if successful
+// Returns -1 if the needle is not found.
+// Without strict, PHP 8 loose comparison (==) is used, so "1e1" matches 10 but "abc" does not match 0.
+// With strict, both type and value must match (===).
+func ArraySearch(needle interface{}, haystack []interface{}, strict bool) int {
+	for i, v := range haystack {
+		if strict {
+			if reflect.DeepEqual(v, needle) {
+				return i
+			}
+		} else if phpEqual(v, needle) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// InArray — Checks if a value exists in an array
+// See ArraySearch for the comparison rules.
+func InArray(needle interface{}, haystack []interface{}, strict bool) bool {
+	return ArraySearch(needle, haystack, strict) != -1
+}
+
+// ArrayFilter — Filters elements of an array using a callback function
+// If callback is nil, all values that PHP considers false ("", "0", 0, 0.0, false, nil, empty
+// arrays) are removed.
+func ArrayFilter(s []interface{}, callback func(interface{}) bool) map[int]interface{} {
+	if callback == nil {
+		callback = phpBool
+	}
+
+	n := make(map[int]interface{})
+	for i, v := range s {
+		if callback(v) {
+			n[i] = v
+		}
+	}
+
+	return n
+}
+
+// ArrayMap — Applies the callback to the elements of the given array
+func ArrayMap(callback func(interface{}) interface{}, s []interface{}) []interface{} {
+	n := make([]interface{}, len(s))
+
+	for i, v := range s {
+		n[i] = callback(v)
+	}
+
+	return n
+}
+
+// ArrayReduce — Iteratively reduce the array to a single value using a callback function
+// Returns initial if the array is empty.
+func ArrayReduce(s []interface{}, callback func(carry, item interface{}) interface{}, initial interface{}) interface{} {
+	carry := initial
+
+	for _, v := range s {
+		carry = callback(carry, v)
+	}
+
+	return carry
+}
+
+// ArrayWalk — Apply a user supplied function to every member of an array
+// The callback receives a pointer to the value so it can modify the array in place, and its index as key.
+func ArrayWalk(s []interface{}, callback func(value *interface{}, key interface{})) {
+	for i := range s {
+		callback(&s[i], i)
+	}
+}
+
+// ArrayWalkRecursive — Apply a user function recursively to every member of an array
+// Nested []interface{} and map[interface{}]interface{} values are descended into instead of being
+// passed to callback.
+func ArrayWalkRecursive(s []interface{}, callback func(value *interface{}, key interface{})) {
+	for i := range s {
+		walkRecursive(&s[i], i, callback)
+	}
+}
+
+func walkRecursive(value *interface{}, key interface{}, callback func(value *interface{}, key interface{})) {
+	switch v := (*value).(type) {
+	case []interface{}:
+		for i := range v {
+			walkRecursive(&v[i], i, callback)
+		}
+	case map[interface{}]interface{}:
+		for k, e := range v {
+			walkRecursive(&e, k, callback)
+			v[k] = e
+		}
+	default:
+		callback(value, key)
+	}
+}
+
+// ArrayCountValues — Counts all the values of an array
+// Only strings and integers are counted. Integer strings such as "1" are counted together with 1,
+// and the resulting key is an int, as in PHP. Other values are ignored.
+func ArrayCountValues(s []interface{}) map[interface{}]int {
+	m := make(map[interface{}]int)
+
+	for _, v := range s {
+		if str, ok := v.(string); ok {
+			if i, err := strconv.Atoi(str); err == nil && strconv.Itoa(i) == str {
+				m[i]++
+			} else {
+				m[str]++
+			}
+		} else if i, ok := phpInt(v); ok {
+			m[int(i)]++
+		}
+	}
+
+	return m
+}
+
+// ArraySum — Calculate the sum of values in an array
+// Values are converted like PHP's arithmetic does: booleans count as 0 or 1, strings as their
+// leading number, so "12abc" is 12, and other values as 0.
+func ArraySum(s []interface{}) float64 {
+	var sum float64
+
+	for _, v := range s {
+		sum += phpFloat(v)
+	}
+
+	return sum
+}
+
+// ArrayProduct — Calculate the product of values in an array
+// The product of an empty array is 1; values are converted like in ArraySum.
+func ArrayProduct(s []interface{}) float64 {
+	product := 1.0
+
+	for _, v := range s {
+		product *= phpFloat(v)
+	}
+
+	return product
+}
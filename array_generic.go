@@ -0,0 +1,214 @@
+package utils
+
+import (
+	"math"
+)
+
+// Fill — Fill an array with values
+func Fill[T any](startIndex int, num uint, value T) map[int]T {
+	m := make(map[int]T, num)
+
+	var i uint
+	for i = 0; i < num; i++ {
+		m[startIndex] = value
+		startIndex++
+	}
+
+	return m
+}
+
+// Flip — Exchanges all keys with their associated values in an array
+func Flip[K, V comparable](m map[K]V) map[V]K {
+	n := make(map[V]K, len(m))
+
+	for k, v := range m {
+		n[v] = k
+	}
+
+	return n
+}
+
+// Keys — Return all the keys of an array
+func Keys[K comparable, V any](elements map[K]V) []K {
+	keys := make([]K, 0, len(elements))
+
+	for key := range elements {
+		keys = append(keys, key)
+	}
+
+	return keys
+}
+
+// Values — Return all the values of an array
+func Values[K comparable, V any](elements map[K]V) []V {
+	vals := make([]V, 0, len(elements))
+
+	for _, val := range elements {
+		vals = append(vals, val)
+	}
+
+	return vals
+}
+
+// Merge — Merge one or more arrays
+func Merge[T any](ss ...[]T) []T {
+	n := 0
+	for _, v := range ss {
+		n += len(v)
+	}
+
+	s := make([]T, 0, n)
+	for _, v := range ss {
+		s = append(s, v...)
+	}
+
+	return s
+}
+
+// Chunk — Split an array into chunks
+func Chunk[T any](s []T, size int) [][]T {
+	if size < 1 {
+		panic("size: cannot be less than 1")
+	}
+
+	length := len(s)
+	chunks := int(math.Ceil(float64(length) / float64(size)))
+
+	n := make([][]T, 0, chunks)
+	for i := 0; i < chunks; i++ {
+		end := (i + 1) * size
+		if end > length {
+			end = length
+		}
+
+		n = append(n, s[i*size:end])
+	}
+
+	return n
+}
+
+// Pad — Pad array to the specified length with a value
+// A positive size pads on the right, a negative size on the left.
+func Pad[T any](s []T, size int, val T) []T {
+	if size == 0 || (size > 0 && size < len(s)) || (size < 0 && size > -len(s)) {
+		return s
+	}
+
+	n := size
+	if size < 0 {
+		n = -size
+	}
+
+	n -= len(s)
+	tmp := make([]T, n)
+	for i := 0; i < n; i++ {
+		tmp[i] = val
+	}
+
+	if size > 0 {
+		return append(s, tmp...)
+	}
+
+	return append(tmp, s...)
+}
+
+// Slice — Extract a slice of the array
+func Slice[T any](s []T, offset, length uint) []T {
+	if offset > uint(len(s)) {
+		panic("offset: the offset is less than the length of s")
+	}
+
+	end := offset + length
+	if end < uint(len(s)) {
+		return s[offset:end]
+	}
+
+	return s[offset:]
+}
+
+// Column — Return the values from a single column in the input array
+func Column[K, C comparable, V any](input map[K]map[C]V, columnKey C) []V {
+	columns := make([]V, 0, len(input))
+
+	for _, val := range input {
+		if v, ok := val[columnKey]; ok {
+			columns = append(columns, v)
+		}
+	}
+
+	return columns
+}
+
+// Push — Push one or more elements onto the end of array
+func Push[T any](s *[]T, elements ...T) int {
+	*s = append(*s, elements...)
+	return len(*s)
+}
+
+// Pop — Pop the element off the end of array
+// The zero value of T is returned when s is empty.
+func Pop[T any](s *[]T) T {
+	var e T
+	if len(*s) == 0 {
+		return e
+	}
+
+	ep := len(*s) - 1
+	e = (*s)[ep]
+	*s = (*s)[:ep]
+
+	return e
+}
+
+// Unshift — Prepend one or more elements to the beginning of an array
+func Unshift[T any](s *[]T, elements ...T) int {
+	n := make([]T, 0, len(elements)+len(*s))
+	n = append(n, elements...)
+	*s = append(n, *s...)
+	return len(*s)
+}
+
+// Shift — Shift an element off the beginning of array
+// The zero value of T is returned when s is empty.
+func Shift[T any](s *[]T) T {
+	var f T
+	if len(*s) == 0 {
+		return f
+	}
+
+	f = (*s)[0]
+	*s = (*s)[1:]
+
+	return f
+}
+
+// KeyExists — Checks if the given key or index exists in the array
+func KeyExists[K comparable, V any](key K, m map[K]V) bool {
+	_, ok := m[key]
+	return ok
+}
+
+// Combine — Creates an array by using one array for keys and another for its values
+func Combine[K comparable, V any](keys []K, values []V) map[K]V {
+	if len(keys) != len(values) {
+		panic("the number of elements for each slice isn't equal")
+	}
+
+	m := make(map[K]V, len(keys))
+	for i, k := range keys {
+		m[k] = values[i]
+	}
+
+	return m
+}
+
+// Reverse — Return an array with elements in reverse order
+// Unlike ArrayReverse, the input slice is left untouched.
+func Reverse[T any](s []T) []T {
+	n := make([]T, len(s))
+	for i, v := range s {
+		n[len(s)-1-i] = v
+	}
+
+	return n
+}
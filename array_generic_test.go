@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"sort"
+	"testing"
+)
+
+func boxed(s []string) []interface{} {
+	n := make([]interface{}, len(s))
+	for i, v := range s {
+		n[i] = v
+	}
+	return n
+}
+
+func unboxed(s []interface{}) []string {
+	n := make([]string, len(s))
+	for i, v := range s {
+		n[i] = v.(string)
+	}
+	return n
+}
+
+func TestArrayGeneric(t *testing.T) {
+	s := []string{"a", "b", "c", "d", "e"}
+
+	for size := 1; size <= 6; size++ {
+		chunks := Chunk(s, size)
+		legacy := ArrayChunk(boxed(s), size)
+		equal(t, len(legacy), len(chunks))
+		for i := range legacy {
+			equal(t, unboxed(legacy[i]), chunks[i])
+		}
+	}
+	equal(t, [][]int{}, Chunk([]int{}, 2))
+
+	equal(t, unboxed(ArrayMerge(boxed(s[:2]), boxed(s[3:]))), Merge(s[:2], s[3:]))
+	equal(t, []string{}, Merge[string]())
+
+	for _, size := range []int{-7, -5, -2, 0, 2, 5, 7} {
+		equal(t, unboxed(ArrayPad(boxed(s), size, "x")), Pad(append([]string(nil), s...), size, "x"))
+	}
+	equal(t, []int{0, 0, 1}, Pad([]int{1}, -3, 0))
+
+	for _, c := range [][2]uint{{0, 0}, {0, 2}, {1, 3}, {4, 10}, {5, 1}} {
+		equal(t, unboxed(ArraySlice(boxed(s), c[0], c[1])), Slice(s, c[0], c[1]))
+	}
+
+	tReverse := Reverse(s)
+	equal(t, []string{"e", "d", "c", "b", "a"}, tReverse)
+	equal(t, []string{"a", "b", "c", "d", "e"}, s)
+	equal(t, unboxed(ArrayReverse(boxed(s))), tReverse)
+
+	tCombine := Combine([]string{"a", "b"}, []int{1, 2})
+	equal(t, map[string]int{"a": 1, "b": 2}, tCombine)
+	legacyCombine := ArrayCombine([]interface{}{"a", "b"}, []interface{}{1, 2})
+	for k, v := range tCombine {
+		equal(t, legacyCombine[k], v)
+	}
+
+	equal(t, map[int]string{1: "a", 2: "b"}, Flip(tCombine))
+
+	tKeys := Keys(tCombine)
+	sort.Strings(tKeys)
+	equal(t, []string{"a", "b"}, tKeys)
+
+	tValues := Values(tCombine)
+	sort.Ints(tValues)
+	equal(t, []int{1, 2}, tValues)
+
+	equal(t, map[int]bool{-1: true, 0: true}, Fill(-1, 2, true))
+	equal(t, true, KeyExists("a", tCombine))
+	equal(t, false, KeyExists("z", tCombine))
+
+	type user struct{ Name string }
+	rows := map[int]map[string]user{
+		1: {"owner": {"ann"}},
+		2: {"owner": {"bob"}},
+		3: {"guest": {"eve"}},
+	}
+	tColumn := Column(rows, "owner")
+	sort.Slice(tColumn, func(i, j int) bool { return tColumn[i].Name < tColumn[j].Name })
+	equal(t, []user{{"ann"}, {"bob"}}, tColumn)
+
+	users := []user{{"ann"}}
+	equal(t, 3, Push(&users, user{"bob"}, user{"eve"}))
+	equal(t, 4, Unshift(&users, user{"zed"}))
+	equal(t, []user{{"zed"}, {"ann"}, {"bob"}, {"eve"}}, users)
+	equal(t, user{"eve"}, Pop(&users))
+	equal(t, user{"zed"}, Shift(&users))
+	equal(t, []user{{"ann"}, {"bob"}}, users)
+
+	var empty []user
+	equal(t, user{}, Pop(&empty))
+	equal(t, user{}, Shift(&empty))
+}
@@ -38,8 +38,9 @@ func TestArray(t *testing.T) {
 	tarrayfill := ArrayFill(-3, 6, "aaa")
 	equal(t, map[int]interface{}{-1: "aaa", 0: "aaa", 1: "aaa", 2: "aaa", -3: "aaa", -2: "aaa"}, tarrayfill)
 
-	tarrayrand := ArrayRand([]interface{}{"a", "b", "c"})
-	equal(t, 3, len(tarrayrand))
+	tarrayrand := ArrayRand([]interface{}{"a", "b", "c"}, 2)
+	equal(t, 2, len(tarrayrand))
+	gt(t, float64(tarrayrand[1]), float64(tarrayrand[0]))
 
 	var s2 = make([]interface{}, 3)
 	s2[0] = "a"
@@ -55,3 +56,125 @@ func TestArray(t *testing.T) {
 	tarraycombine := ArrayCombine(s2, s3)
 	equal(t, map[interface{}]interface{}{"a": "x", "b": "y", "c": "z"}, tarraycombine)
 }
+
+func TestArrayCompare(t *testing.T) {
+	s := []interface{}{"a", 1, "1", 1.5, true, nil, "", "0", 0}
+
+	diffs := []struct {
+		s, other []interface{}
+		expected map[int]interface{}
+	}{
+		{[]interface{}{"a", "b", "c"}, []interface{}{"b"}, map[int]interface{}{0: "a", 2: "c"}},
+		{[]interface{}{1, "2", 3.0}, []interface{}{"1", 2, "3"}, map[int]interface{}{}},
+		{[]interface{}{true, false, nil, ""}, []interface{}{""}, map[int]interface{}{0: true}},
+		{[]interface{}{"1.0", 1.0}, []interface{}{1}, map[int]interface{}{0: "1.0"}},
+		{[]interface{}{0.1 + 0.2}, []interface{}{"0.3"}, map[int]interface{}{}},
+		{[]interface{}{"x", "a", "x", "b", "x"}, []interface{}{"x"}, map[int]interface{}{1: "a", 3: "b"}},
+	}
+	for _, c := range diffs {
+		equal(t, c.expected, ArrayDiff(c.s, c.other))
+	}
+
+	intersects := []struct {
+		s, other []interface{}
+		expected map[int]interface{}
+	}{
+		{[]interface{}{"a", "b", "c"}, []interface{}{"c", "b"}, map[int]interface{}{1: "b", 2: "c"}},
+		{[]interface{}{1, "2", 3.5}, []interface{}{"1", 2}, map[int]interface{}{0: 1, 1: "2"}},
+		{[]interface{}{nil, false}, []interface{}{""}, map[int]interface{}{0: nil, 1: false}},
+		{[]interface{}{"x", "a", "x", "b", "x"}, []interface{}{"x", "b"}, map[int]interface{}{0: "x", 2: "x", 3: "b", 4: "x"}},
+	}
+	for _, c := range intersects {
+		equal(t, c.expected, ArrayIntersect(c.s, c.other))
+	}
+	equal(t, map[int]interface{}{2: "c"}, ArrayIntersect([]interface{}{"a", "b", "c"}, []interface{}{"c", "b"}, []interface{}{"c"}))
+
+	m1 := map[interface{}]interface{}{"a": "green", "b": "brown", "c": "blue", 0: "red"}
+	m2 := map[interface{}]interface{}{"a": "green", "b": "yellow", 0: "blue", 1: "red"}
+	equal(t, map[interface{}]interface{}{"c": "blue"}, ArrayDiffKey(m1, m2))
+	equal(t, map[interface{}]interface{}{"b": "brown", "c": "blue", 0: "red"}, ArrayDiffAssoc(m1, m2))
+	equal(t, map[interface{}]interface{}{"a": "green", "b": "brown", 0: "red"}, ArrayIntersectKey(m1, m2))
+	equal(t, map[interface{}]interface{}{"a": "green"}, ArrayIntersectAssoc(m1, m2))
+	equal(t, map[interface{}]interface{}{0: 1}, ArrayDiffAssoc(map[interface{}]interface{}{0: 1, 1: 2}, map[interface{}]interface{}{1: "2"}))
+
+	equal(t, map[int]interface{}{0: "a", 1: 1, 3: 1.5, 5: nil, 7: "0"}, ArrayUnique(s))
+	uniques := []struct {
+		s        []interface{}
+		expected map[int]interface{}
+	}{
+		{[]interface{}{1, "1", 2}, map[int]interface{}{0: 1, 2: 2}},
+		{[]interface{}{"b", "a", "b", "c", "a"}, map[int]interface{}{0: "b", 1: "a", 3: "c"}},
+		{[]interface{}{nil, "", false, 0}, map[int]interface{}{0: nil, 3: 0}},
+		{[]interface{}{}, map[int]interface{}{}},
+	}
+	for _, c := range uniques {
+		equal(t, c.expected, ArrayUnique(c.s))
+	}
+
+	searches := []struct {
+		needle   interface{}
+		strict   bool
+		expected int
+	}{
+		{"a", false, 0},
+		{"1", false, 1},
+		{"1", true, 2},
+		{1.0, true, -1},
+		{1.5, false, 3},
+		{"1.5", false, 3},
+		{"1e0", false, 1},
+		{false, false, 5},
+		{nil, false, 5},
+		{nil, true, 5},
+		{0, false, 5},
+		{0, true, 8},
+		{"abc", false, 4},
+		{"abc", true, -1},
+		{2, false, 4},
+		{2, true, -1},
+	}
+	for _, c := range searches {
+		equal(t, c.expected, ArraySearch(c.needle, s, c.strict))
+		equal(t, c.expected != -1, InArray(c.needle, s, c.strict))
+	}
+	equal(t, -1, ArraySearch("abc", []interface{}{0}, false))
+	equal(t, 0, ArraySearch(" 1", []interface{}{"1"}, false))
+
+	equal(t, map[int]interface{}{0: "a", 1: 1, 2: "1", 3: 1.5, 4: true}, ArrayFilter(s, nil))
+	equal(t, map[int]interface{}{1: 5, 3: 7}, ArrayFilter([]interface{}{0, 5, "", 7}, nil))
+	equal(t, map[int]interface{}{1: 1, 2: "1"}, ArrayFilter(s, func(v interface{}) bool {
+		return phpString(v) == "1" && v != true
+	}))
+
+	tArrayMap := ArrayMap(func(v interface{}) interface{} { return v.(int) * 2 }, []interface{}{1, 2, 3})
+	equal(t, []interface{}{2, 4, 6}, tArrayMap)
+
+	sum := func(carry, item interface{}) interface{} { return carry.(int) + item.(int) }
+	equal(t, 6, ArrayReduce([]interface{}{1, 2, 3}, sum, 0))
+	equal(t, "empty", ArrayReduce([]interface{}{}, sum, "empty"))
+
+	walked := []interface{}{"a", "b"}
+	ArrayWalk(walked, func(v *interface{}, k interface{}) { *v = phpString(k) + ":" + (*v).(string) })
+	equal(t, []interface{}{"0:a", "1:b"}, walked)
+
+	nested := []interface{}{1, []interface{}{2, 3}, map[interface{}]interface{}{"x": 4}}
+	var keys []interface{}
+	ArrayWalkRecursive(nested, func(v *interface{}, k interface{}) {
+		keys = append(keys, k)
+		*v = (*v).(int) * 10
+	})
+	equal(t, []interface{}{10, []interface{}{20, 30}, map[interface{}]interface{}{"x": 40}}, nested)
+	equal(t, []interface{}{0, 0, 1, "x"}, keys)
+
+	tArrayCountValues := ArrayCountValues([]interface{}{1, "hello", 1, "world", "hello", "1", "01", 1.5, true})
+	equal(t, map[interface{}]int{1: 3, "hello": 2, "world": 1, "01": 1}, tArrayCountValues)
+
+	equal(t, 11.5, ArraySum([]interface{}{1, "2", 3.5, true, "x", nil, "4"}))
+	equal(t, float64(0), ArraySum([]interface{}{}))
+	equal(t, 24.0, ArrayProduct([]interface{}{2, "3", 4.0, true}))
+	equal(t, float64(1), ArrayProduct([]interface{}{}))
+	equal(t, float64(0), ArrayProduct([]interface{}{2, "x"}))
+	equal(t, 13.0, ArraySum([]interface{}{"12abc", 1}))
+	equal(t, 6.0, ArrayProduct([]interface{}{"3 apples", 2}))
+	equal(t, 3.5, ArraySum([]interface{}{" 1.5e0x", "2.", "-", ".abc"}))
+}
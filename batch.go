@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// BatchPolicy — What RunBatch does when a command fails
+type BatchPolicy int
+
+// Batch policies
+const (
+	BatchCollectAll BatchPolicy = iota // run every command
+	BatchFailFast                      // cancel the running commands and skip the others
+)
+
+// ErrSkipped — The error of a command RunBatch did not start because an earlier one failed
+var ErrSkipped = errors.New("skipped after a failure")
+
+// BatchOptions — Settings of RunBatch
+// At most Concurrency commands run at once, runtime.NumCPU() when it is 0. Run applies to every
+// command, its Timeout to each of them; its writers and line callbacks receive the output of all
+// the commands, one write at a time. Its Stdin must be nil: the commands cannot share one input.
+// OnProgress is called once a command is over, never concurrently, with the number of commands
+// over so far.
+type BatchOptions struct {
+	Concurrency int
+	Policy      BatchPolicy
+	Run         *RunOptions
+	OnProgress  func(index int, r *Result, done, total int)
+}
+
+// BatchError — The failures of RunBatch, in input order
+// It unwraps to the error of every failed command.
+type BatchError struct {
+	Indexes []int
+	Errs    []error
+	Total   int
+}
+
+func (e *BatchError) Error() string {
+	msgs := make([]string, len(e.Errs))
+	for i, err := range e.Errs {
+		msgs[i] = err.Error()
+	}
+	return fmt.Sprintf("%d of %d commands failed: %s", len(e.Errs), e.Total, strings.Join(msgs, "; "))
+}
+
+func (e *BatchError) Unwrap() []error {
+	return e.Errs
+}
+
+// RunBatch — Execute commands concurrently and return their results in the order of commands
+// Each command is run by RunContext. The error is nil when all of them succeeded and a *BatchError
+// otherwise; with BatchFailFast the first failure cancels the running commands, and those not
+// started yet get a Result whose Err wraps ErrSkipped.
+// results, err := RunBatch(ctx, commands, &BatchOptions{Concurrency: 8, Run: &RunOptions{Timeout: time.Minute}})
+func RunBatch(ctx context.Context, commands []string, opts *BatchOptions) ([]*Result, error) {
+	if opts == nil {
+		opts = &BatchOptions{}
+	}
+	if opts.Concurrency < 0 {
+		panic("concurrency: cannot be negative")
+	}
+	if opts.Run != nil && opts.Run.Stdin != nil {
+		panic("stdin: cannot be shared by the commands of a batch")
+	}
+	workers := opts.Concurrency
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+	runOpts := &RunOptions{}
+	if opts.Run != nil {
+		runOpts = sharedOptions(opts.Run)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([]*Result, len(commands))
+	var mu sync.Mutex // guards failed, done and the calls of OnProgress
+	failed, done := false, 0
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers && w < len(commands); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				mu.Lock()
+				skip := failed && opts.Policy == BatchFailFast
+				mu.Unlock()
+
+				r := &Result{Command: commands[i], ExitCode: -1, Err: &CommandError{Command: commands[i], ExitCode: -1, Err: ErrSkipped}}
+				if !skip {
+					r = RunContext(ctx, commands[i], runOpts)
+				}
+				results[i] = r
+
+				mu.Lock()
+				done++
+				if r.Err != nil && !failed {
+					failed = true
+					if opts.Policy == BatchFailFast {
+						cancel()
+					}
+				}
+				if opts.OnProgress != nil {
+					opts.OnProgress(i, r, done, len(commands))
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for i := range commands {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	batchErr := &BatchError{Total: len(commands)}
+	for i, r := range results {
+		if r.Err != nil {
+			batchErr.Indexes = append(batchErr.Indexes, i)
+			batchErr.Errs = append(batchErr.Errs, r.Err)
+		}
+	}
+	if len(batchErr.Errs) > 0 {
+		return results, batchErr
+	}
+	return results, nil
+}
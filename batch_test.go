@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingExecutor records how many commands run at once
+type countingExecutor struct {
+	mu          sync.Mutex
+	active, max int
+}
+
+func (e *countingExecutor) Run(ctx context.Context, cmd Command, opts *RunOptions) *Result {
+	e.mu.Lock()
+	e.active++
+	if e.active > e.max {
+		e.max = e.active
+	}
+	e.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	e.mu.Lock()
+	e.active--
+	e.mu.Unlock()
+	return &Result{Command: cmd.Line}
+}
+
+func TestRunBatch(t *testing.T) {
+	counting := &countingExecutor{}
+	defer SetExecutor(SetExecutor(counting))
+	commands := make([]string, 20)
+	for i := range commands {
+		commands[i] = "ping host" + string(rune('a'+i))
+	}
+	var progress []int
+	results, err := RunBatch(context.Background(), commands, &BatchOptions{Concurrency: 3, OnProgress: func(index int, r *Result, done, total int) {
+		equal(t, commands[index], r.Command)
+		equal(t, 20, total)
+		progress = append(progress, done)
+	}})
+	equal(t, nil, err)
+	equal(t, 3, counting.max)
+	equal(t, 20, len(progress))
+	equal(t, 20, progress[19])
+	for i, r := range results {
+		equal(t, commands[i], r.Command)
+	}
+
+	fake := NewFakeExecutor().
+		Expect("slow", FakeResponse{Stdout: "slow\n", Delay: 50 * time.Millisecond}).
+		Expect("ok", FakeResponse{Stdout: "ok\n"}).
+		Expect("bad", FakeResponse{ExitCode: 1}).
+		Expect("hang", FakeResponse{Delay: time.Hour})
+	SetExecutor(fake)
+
+	// results keep the input order
+	results, err = RunBatch(context.Background(), []string{"slow", "ok", "bad", "ok"}, &BatchOptions{Concurrency: 4})
+	equal(t, "slow\n", string(results[0].Stdout))
+	equal(t, "ok\n", string(results[1].Stdout))
+	var batchErr *BatchError
+	equal(t, true, errors.As(err, &batchErr))
+	equal(t, []int{2}, batchErr.Indexes)
+	equal(t, "1 of 4 commands failed: bad: exit status 1", err.Error())
+
+	// per-command timeouts
+	results, err = RunBatch(context.Background(), []string{"hang", "ok"}, &BatchOptions{Run: &RunOptions{Timeout: 50 * time.Millisecond}})
+	equal(t, true, errors.Is(err, context.DeadlineExceeded))
+	equal(t, nil, results[1].Err)
+
+	// fail fast: the running command is canceled and the others skipped
+	results, err = RunBatch(context.Background(), []string{"hang", "bad", "ok", "ok"}, &BatchOptions{Concurrency: 2, Policy: BatchFailFast})
+	equal(t, true, errors.Is(results[0].Err, context.Canceled))
+	equal(t, 1, results[1].ExitCode)
+	equal(t, true, errors.Is(results[2].Err, ErrSkipped))
+	equal(t, true, errors.Is(results[3].Err, ErrSkipped))
+	equal(t, true, errors.As(err, &batchErr))
+	equal(t, []int{0, 1, 2, 3}, batchErr.Indexes)
+
+	results, err = RunBatch(context.Background(), nil, nil)
+	equal(t, 0, len(results))
+	equal(t, nil, err)
+
+	// the commands would split a shared input between them
+	func() {
+		defer func() { equal(t, "stdin: cannot be shared by the commands of a batch", recover()) }()
+		RunBatch(context.Background(), []string{"ok"}, &BatchOptions{Run: &RunOptions{Stdin: strings.NewReader("x")}})
+	}()
+}
@@ -0,0 +1,423 @@
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HolidayKind — How a Holiday's date is found in a given year
+type HolidayKind int
+
+// Holiday kinds
+const (
+	HolidayFixed      HolidayKind = iota // the same month and day every year
+	HolidayNthWeekday                    // the Nth weekday of a month, a negative Nth counts from the end
+	HolidayEaster                        // Offset days from Gregorian Easter Sunday
+)
+
+// Holiday — A rule producing one non-business day per year
+// When Observed is set and the holiday falls on a weekend, the next business day is taken off
+// instead, skipping days already taken by other holidays.
+// Holiday{Name: "Christmas Day", Kind: HolidayFixed, Month: time.December, Day: 25, Observed: true}
+// Holiday{Name: "Memorial Day", Kind: HolidayNthWeekday, Month: time.May, Weekday: time.Monday, Nth: -1}
+// Holiday{Name: "Good Friday", Kind: HolidayEaster, Offset: -2}
+type Holiday struct {
+	Name     string
+	Kind     HolidayKind
+	Month    time.Month
+	Day      int
+	Weekday  time.Weekday
+	Nth      int
+	Offset   int
+	Observed bool
+}
+
+// date returns the holiday's date in year, ok is false when the rule has none that year
+func (h Holiday) date(year int) (t time.Time, ok bool) {
+	switch h.Kind {
+	case HolidayFixed:
+		if !CheckDate(int(h.Month), h.Day, year) {
+			return t, false
+		}
+		return time.Date(year, h.Month, h.Day, 0, 0, 0, 0, time.UTC), true
+	case HolidayNthWeekday:
+		if h.Nth > 0 {
+			first := time.Date(year, h.Month, 1, 0, 0, 0, 0, time.UTC)
+			t = first.AddDate(0, 0, (int(h.Weekday)-int(first.Weekday())+7)%7+(h.Nth-1)*7)
+		} else if h.Nth < 0 {
+			last := time.Date(year, h.Month+1, 0, 0, 0, 0, 0, time.UTC)
+			t = last.AddDate(0, 0, -((int(last.Weekday())-int(h.Weekday)+7)%7)+(h.Nth+1)*7)
+		}
+		return t, h.Nth != 0 && t.Month() == h.Month
+	case HolidayEaster:
+		month, day := easterSunday(year)
+		return time.Date(year, month, day+h.Offset, 0, 0, 0, 0, time.UTC), true
+	}
+
+	return t, false
+}
+
+// Calendar — Business days: every day that is neither a weekend day nor a holiday
+// Dates are compared on the wall clock of the times passed in. It is safe for concurrent use.
+// c := NewCalendar()
+// c.AddHoliday(Holiday{Name: "New Year's Day", Kind: HolidayFixed, Month: time.January, Day: 1, Observed: true})
+// c.AddBusinessDays(friday, 1) // the next Monday
+type Calendar struct {
+	mu       sync.Mutex
+	weekend  [7]bool
+	holidays []Holiday
+	years    map[int]map[int]string // year, day of year -> holiday name
+}
+
+// NewCalendar — Create a calendar with the given weekend days and no holidays
+// Without arguments the weekend is Saturday and Sunday.
+func NewCalendar(weekend ...time.Weekday) *Calendar {
+	if len(weekend) == 0 {
+		weekend = []time.Weekday{time.Saturday, time.Sunday}
+	}
+
+	c := &Calendar{}
+	c.SetWeekend(weekend...)
+	return c
+}
+
+// SetWeekend — Replace the weekend days, passing none makes every weekday a business day
+func (c *Calendar) SetWeekend(days ...time.Weekday) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.weekend = [7]bool{}
+	for _, d := range days {
+		c.weekend[d] = true
+	}
+	c.years = nil
+}
+
+// AddHoliday — Add holiday rules to the calendar
+func (c *Calendar) AddHoliday(holidays ...Holiday) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.holidays = append(c.holidays, holidays...)
+	c.years = nil
+}
+
+// Holidays — Return the days off in year with their names, observed holidays on the day taken off
+func (c *Calendar) Holidays(year int) map[time.Time]string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	days := make(map[time.Time]string)
+	for yday, name := range c.year(year) {
+		days[time.Date(year, time.January, yday, 0, 0, 0, 0, time.UTC)] = name
+	}
+	return days
+}
+
+// year returns the days off in year by day of year, c.mu must be held
+func (c *Calendar) year(year int) map[int]string {
+	if days, ok := c.years[year]; ok {
+		return days
+	}
+
+	type off struct {
+		date time.Time
+		Holiday
+	}
+
+	// a holiday observed late in the previous year can move into this one
+	var all []off
+	for _, y := range []int{year - 1, year} {
+		for _, h := range c.holidays {
+			if t, ok := h.date(y); ok {
+				all = append(all, off{t, h})
+			}
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].date.Before(all[j].date) })
+
+	taken := make(map[time.Time]string, len(all))
+	for _, o := range all {
+		if _, ok := taken[o.date]; !ok {
+			taken[o.date] = o.Name
+		}
+	}
+	for _, o := range all {
+		if !o.Observed || !c.weekend[o.date.Weekday()] {
+			continue
+		}
+
+		t := o.date
+		for i := 0; i <= 7+len(all); i++ {
+			if _, ok := taken[t]; !ok && !c.weekend[t.Weekday()] {
+				taken[t] = o.Name + " (observed)"
+				break
+			}
+			t = t.AddDate(0, 0, 1)
+		}
+	}
+
+	days := make(map[int]string)
+	for t, name := range taken {
+		if t.Year() == year {
+			days[t.YearDay()] = name
+		}
+	}
+
+	if c.years == nil {
+		c.years = make(map[int]map[int]string)
+	}
+	c.years[year] = days
+	return days
+}
+
+// HolidayName — Return the name of the holiday t falls on, ok is false on other days
+func (c *Calendar) HolidayName(t time.Time) (name string, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	name, ok = c.year(t.Year())[t.YearDay()]
+	return name, ok
+}
+
+// IsBusinessDay — Check whether t falls on a business day
+func (c *Calendar) IsBusinessDay(t time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.isBusinessDay(t)
+}
+
+// isBusinessDay checks t, c.mu must be held
+func (c *Calendar) isBusinessDay(t time.Time) bool {
+	if c.weekend[t.Weekday()] {
+		return false
+	}
+	_, holiday := c.year(t.Year())[t.YearDay()]
+	return !holiday
+}
+
+// step moves t one day forward or back on the wall clock, c.mu must be held
+func (c *Calendar) step(t time.Time, dir int) time.Time {
+	if c.weekend == [7]bool{true, true, true, true, true, true, true} {
+		panic("weekend: cannot be every day")
+	}
+
+	for i := 0; ; i++ {
+		t = t.AddDate(0, 0, dir)
+		if c.isBusinessDay(t) {
+			return t
+		}
+		if i > 366 {
+			panic("holidays: no business day in a year")
+		}
+	}
+}
+
+// NextBusinessDay — Return the first business day after t, at the same time of day
+func (c *Calendar) NextBusinessDay(t time.Time) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.step(t, 1)
+}
+
+// PreviousBusinessDay — Return the last business day before t, at the same time of day
+func (c *Calendar) PreviousBusinessDay(t time.Time) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.step(t, -1)
+}
+
+// AddBusinessDays — Return t moved n business days, at the same time of day
+// A negative n moves back. Starting on a non-business day, one day moves to the next business day.
+// AddBusinessDays(friday, 1) == monday
+// AddBusinessDays(saturday, 1) == monday
+func (c *Calendar) AddBusinessDays(t time.Time, n int) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	dir := 1
+	if n < 0 {
+		dir, n = -1, -n
+	}
+	for ; n > 0; n-- {
+		t = c.step(t, dir)
+	}
+	return t
+}
+
+// BusinessDaysBetween — Count the business days after from up to and including to
+// The count is negative when to is before from, so AddBusinessDays(from, BusinessDaysBetween(from, to))
+// is to whenever to is a business day. Times are compared by their date only.
+func (c *Calendar) BusinessDaysBetween(from, to time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	a := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	b := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	sign := 1
+	if b.Before(a) {
+		a, b, sign = b, a, -1
+	}
+
+	n := 0
+	for t := a.AddDate(0, 0, 1); !t.After(b); t = t.AddDate(0, 0, 1) {
+		if c.isBusinessDay(t) {
+			n++
+		}
+	}
+	if sign < 0 {
+		// counted (to, from], the days after to up to and including from
+		return -n
+	}
+	return n
+}
+
+// LoadCalendar — Read a calendar from a holiday definition file, see ParseCalendar
+func LoadCalendar(filename string) (*Calendar, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseCalendar(f)
+}
+
+// ParseCalendar — Read a calendar from holiday definitions, one per line
+// Blank lines and lines starting with # are ignored. Months and weekdays are numbers or English names.
+// Without a weekend line the weekend is Saturday and Sunday; "weekend" alone means no weekend days.
+//
+//	weekend  sat sun
+//	fixed    jan 1              observed  New Year's Day
+//	nth      3 mon jan                    Martin Luther King Jr. Day
+//	nth      last mon may                 Memorial Day
+//	easter   -2                           Good Friday
+//	easter   +1                           Easter Monday
+//	fixed    12 25              observed  Christmas Day
+func ParseCalendar(r io.Reader) (*Calendar, error) {
+	c := NewCalendar()
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if strings.ToLower(fields[0]) == "weekend" {
+			var days []time.Weekday
+			for _, f := range fields[1:] {
+				d, ok := holidayWeekday(f)
+				if !ok {
+					return nil, fmt.Errorf("line %d: unknown weekday %q", line, f)
+				}
+				days = append(days, d)
+			}
+			c.SetWeekend(days...)
+			continue
+		}
+
+		h, err := parseHoliday(fields)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		c.AddHoliday(h)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// parseHoliday parses the fields of a holiday definition line
+func parseHoliday(fields []string) (h Holiday, err error) {
+	var args int
+	switch strings.ToLower(fields[0]) {
+	case "fixed":
+		h.Kind, args = HolidayFixed, 2
+	case "nth":
+		h.Kind, args = HolidayNthWeekday, 3
+	case "easter":
+		h.Kind, args = HolidayEaster, 1
+	default:
+		return h, fmt.Errorf("unknown holiday kind %q", fields[0])
+	}
+	if len(fields) < args+2 {
+		return h, fmt.Errorf("%s holiday needs %d values and a name", fields[0], args)
+	}
+
+	var ok bool
+	switch h.Kind {
+	case HolidayFixed:
+		if h.Month, ok = holidayMonth(fields[1]); !ok {
+			return h, fmt.Errorf("unknown month %q", fields[1])
+		}
+		if h.Day, err = strconv.Atoi(fields[2]); err != nil || !CheckDate(int(h.Month), h.Day, 2000) {
+			return h, fmt.Errorf("invalid day %q", fields[2])
+		}
+	case HolidayNthWeekday:
+		if strings.ToLower(fields[1]) == "last" {
+			h.Nth = -1
+		} else if h.Nth, err = strconv.Atoi(fields[1]); err != nil || h.Nth == 0 || h.Nth < -5 || h.Nth > 5 {
+			return h, fmt.Errorf("invalid week number %q", fields[1])
+		}
+		if h.Weekday, ok = holidayWeekday(fields[2]); !ok {
+			return h, fmt.Errorf("unknown weekday %q", fields[2])
+		}
+		if h.Month, ok = holidayMonth(fields[3]); !ok {
+			return h, fmt.Errorf("unknown month %q", fields[3])
+		}
+	case HolidayEaster:
+		if h.Offset, err = strconv.Atoi(fields[1]); err != nil {
+			return h, fmt.Errorf("invalid offset %q", fields[1])
+		}
+	}
+
+	name := fields[args+1:]
+	if strings.ToLower(name[0]) == "observed" {
+		h.Observed, name = true, name[1:]
+	}
+	if len(name) == 0 {
+		return h, fmt.Errorf("holiday has no name")
+	}
+	h.Name = strings.Join(name, " ")
+
+	return h, nil
+}
+
+// holidayMonth parses a month number or English month name
+func holidayMonth(s string) (time.Month, bool) {
+	if m, err := strconv.Atoi(s); err == nil {
+		return time.Month(m), m >= 1 && m <= 12
+	}
+	s = strings.ToLower(s)
+	if strings.Trim(s, "ivx") == "" {
+		// roman numerals are months for strtotime only
+		return 0, false
+	}
+	m, ok := monthNames[s]
+	return time.Month(m), ok
+}
+
+// holidayWeekday parses an English weekday name, full or abbreviated to three letters
+func holidayWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if len(s) >= 3 && strings.HasPrefix(name, s) {
+			return d, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,130 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const ukHolidays = `
+# England and Wales bank holidays
+weekend  sat sun
+fixed    jan 1        observed  New Year's Day
+easter   -2                     Good Friday
+easter   +1                     Easter Monday
+nth      1 mon may              Early May bank holiday
+nth      last mon may           Spring bank holiday
+nth      -1 Monday August       Summer bank holiday
+fixed    12 25        observed  Christmas Day
+fixed    Dec 26       observed  Boxing Day
+`
+
+func TestCalendar(t *testing.T) {
+	day := func(y, m, d int) time.Time {
+		return time.Date(y, time.Month(m), d, 9, 30, 0, 0, time.UTC)
+	}
+
+	for year, easter := range map[int]string{2018: "04-01", 2019: "04-21", 2024: "03-31", 2038: "04-25", 1818: "03-22", 2285: "03-22"} {
+		m, d := easterSunday(year)
+		equal(t, easter, time.Date(year, m, d, 0, 0, 0, 0, time.UTC).Format("01-02"))
+	}
+
+	c, err := ParseCalendar(strings.NewReader(ukHolidays))
+	equal(t, nil, err)
+
+	names := map[string]string{}
+	for d, name := range c.Holidays(2022) {
+		names[d.Format("01-02")] = name
+	}
+	equal(t, map[string]string{
+		"01-03": "New Year's Day (observed)",
+		"04-15": "Good Friday",
+		"04-18": "Easter Monday",
+		"05-02": "Early May bank holiday",
+		"05-30": "Spring bank holiday",
+		"08-29": "Summer bank holiday",
+		"12-25": "Christmas Day",
+		"12-26": "Boxing Day",
+		"12-27": "Christmas Day (observed)",
+		"01-01": "New Year's Day",
+	}, names)
+
+	// both Christmas and Boxing Day on a weekend
+	equal(t, false, c.IsBusinessDay(day(2021, 12, 27)))
+	equal(t, false, c.IsBusinessDay(day(2021, 12, 28)))
+	equal(t, true, c.IsBusinessDay(day(2021, 12, 29)))
+	name, ok := c.HolidayName(day(2021, 12, 28))
+	equal(t, "Boxing Day (observed)", name)
+	equal(t, true, ok)
+	_, ok = c.HolidayName(day(2021, 12, 29))
+	equal(t, false, ok)
+
+	equal(t, day(2021, 12, 29), c.NextBusinessDay(day(2021, 12, 24)))
+	equal(t, day(2021, 12, 24), c.PreviousBusinessDay(day(2021, 12, 29)))
+	equal(t, day(2018, 4, 3), c.AddBusinessDays(day(2018, 3, 29), 1))
+	equal(t, day(2018, 3, 29), c.AddBusinessDays(day(2018, 4, 3), -1))
+	equal(t, day(2018, 4, 9), c.AddBusinessDays(day(2018, 3, 29), 5))
+	equal(t, day(2018, 3, 29), c.AddBusinessDays(day(2018, 3, 29), 0))
+	equal(t, day(2018, 4, 3), c.AddBusinessDays(day(2018, 3, 31), 1))
+
+	equal(t, 5, c.BusinessDaysBetween(day(2018, 3, 29), day(2018, 4, 9)))
+	equal(t, -5, c.BusinessDaysBetween(day(2018, 4, 9), day(2018, 3, 29)))
+	equal(t, 0, c.BusinessDaysBetween(day(2018, 3, 29), day(2018, 4, 2)))
+	equal(t, 0, c.BusinessDaysBetween(day(2018, 3, 29), day(2018, 3, 29).Add(time.Hour)))
+	equal(t, 253, c.BusinessDaysBetween(day(2017, 12, 31), day(2018, 12, 31)))
+	a, b := day(2018, 1, 10), day(2018, 11, 20)
+	equal(t, b, c.AddBusinessDays(a, c.BusinessDaysBetween(a, b)))
+	equal(t, a, c.AddBusinessDays(b, c.BusinessDaysBetween(b, a)))
+
+	// a holiday observed in the next year
+	c.AddHoliday(Holiday{Name: "New Year's Eve", Kind: HolidayFixed, Month: time.December, Day: 31, Observed: true})
+	name, _ = c.HolidayName(day(2023, 1, 2))
+	equal(t, "New Year's Eve (observed)", name)
+
+	// the time of day and location are kept across DST changes
+	amsterdam, _ := time.LoadLocation("Europe/Amsterdam")
+	equal(t, time.Date(2021, 3, 29, 9, 0, 0, 0, amsterdam), NewCalendar().AddBusinessDays(time.Date(2021, 3, 26, 9, 0, 0, 0, amsterdam), 1))
+
+	// Friday and Saturday weekend, Friday holiday moved to Sunday
+	gulf := NewCalendar(time.Friday, time.Saturday)
+	gulf.AddHoliday(Holiday{Name: "National Day", Kind: HolidayFixed, Month: time.December, Day: 2, Observed: true})
+	equal(t, false, gulf.IsBusinessDay(day(2022, 12, 4)))
+	equal(t, true, gulf.IsBusinessDay(day(2022, 12, 5)))
+	equal(t, day(2022, 12, 5), gulf.NextBusinessDay(day(2022, 12, 1)))
+
+	us := NewCalendar()
+	us.AddHoliday(
+		Holiday{Name: "Martin Luther King Jr. Day", Kind: HolidayNthWeekday, Month: time.January, Weekday: time.Monday, Nth: 3},
+		Holiday{Name: "Thanksgiving", Kind: HolidayNthWeekday, Month: time.November, Weekday: time.Thursday, Nth: 4},
+		Holiday{Name: "Fifth Friday", Kind: HolidayNthWeekday, Month: time.February, Weekday: time.Friday, Nth: 5},
+	)
+	equal(t, false, us.IsBusinessDay(day(2018, 1, 15)))
+	equal(t, false, us.IsBusinessDay(day(2018, 11, 22)))
+	equal(t, 2, len(us.Holidays(2018)))
+	equal(t, 3, len(us.Holidays(2036)))
+
+	none := NewCalendar()
+	none.SetWeekend()
+	equal(t, day(2018, 4, 1), none.NextBusinessDay(day(2018, 3, 31)))
+
+	invalid := []string{
+		"weekend funday",
+		"fixed feb 30 Nonsense",
+		"fixed 13 1 Nonsense",
+		"fixed iv 1 Roman",
+		"fixed jan 1",
+		"fixed jan 1 observed",
+		"nth 0 mon may Zeroth",
+		"nth 1 xyz may Unknown",
+		"easter x Unknown",
+		"monthly 1 Unknown",
+	}
+	for _, line := range invalid {
+		_, err := ParseCalendar(strings.NewReader("# comment\n" + line))
+		unequal(t, nil, err)
+		equal(t, true, strings.HasPrefix(err.Error(), "line 2: "))
+	}
+
+	_, err = LoadCalendar("testdata/does-not-exist")
+	unequal(t, nil, err)
+}
@@ -0,0 +1,568 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Calendars, with the same values as PHP's CAL_* constants
+const (
+	CalGregorian = 0
+	CalJulian    = 1
+	CalJewish    = 2
+	CalFrench    = 3
+)
+
+// JdDayOfWeek modes, with the same values as PHP's CAL_DOW_* constants
+const (
+	CalDowDayNo = 0 // the day number, 0 for Sunday through 6 for Saturday
+	CalDowLong  = 1 // the English day name
+	CalDowShort = 2 // the abbreviated English day name
+)
+
+// JdMonthName modes, with the same values as PHP's CAL_MONTH_* constants
+const (
+	CalMonthGregorianShort = 0
+	CalMonthGregorianLong  = 1
+	CalMonthJulianShort    = 2
+	CalMonthJulianLong     = 3
+	CalMonthJewish         = 4
+	CalMonthFrench         = 5
+)
+
+// EasterDate and EasterDays modes, with the same values as PHP's CAL_EASTER_* constants
+const (
+	CalEasterDefault         = 0 // Julian before 1753, Gregorian after
+	CalEasterRoman           = 1 // Julian before 1583, Gregorian after
+	CalEasterAlwaysGregorian = 2
+	CalEasterAlwaysJulian    = 3
+)
+
+// The calendar conversions are ports of PHP's ext/calendar, which counts days as Serial Day Numbers:
+// the Julian Day at noon, 1 for November 25, 4714 BC in the proleptic Gregorian calendar. A zero day
+// number means the date is out of range. Years are numbered without a year 0, -1 is 1 BC.
+const (
+	gregorianSdnOffset = 32045
+	julianSdnOffset    = 32083
+	frenchSdnOffset    = 2375474
+	frenchFirstValid   = 2375840
+	frenchLastValid    = 2380952
+	daysPer5Months     = 153
+	daysPer4Years      = 1461
+	daysPer400Years    = 146097
+	unixEpochJd        = 2440588
+
+	jewishSdnOffset        = 347997
+	jewishSdnMax           = 324542846
+	newMoonOfCreation      = 31524
+	halakimPerHour         = 1080
+	halakimPerDay          = 25920
+	halakimPerLunarCycle   = 29*halakimPerDay + 13753
+	halakimPerMetonicCycle = halakimPerLunarCycle * (12*19 + 7)
+)
+
+var (
+	monthNamesShort      = [13]string{"", "Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+	monthNamesLong       = [13]string{"", "January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"}
+	frenchMonthNames     = [14]string{"", "Vendemiaire", "Brumaire", "Frimaire", "Nivose", "Pluviose", "Ventose", "Germinal", "Floreal", "Prairial", "Messidor", "Thermidor", "Fructidor", "Extra"}
+	jewishMonthNames     = [14]string{"", "Tishri", "Heshvan", "Kislev", "Tevet", "Shevat", "", "Adar", "Nisan", "Iyyar", "Sivan", "Tammuz", "Av", "Elul"}
+	jewishMonthNamesLeap = [14]string{"", "Tishri", "Heshvan", "Kislev", "Tevet", "Shevat", "Adar I", "Adar II", "Nisan", "Iyyar", "Sivan", "Tammuz", "Av", "Elul"}
+
+	// months in each year of the 19 year metonic cycle, and the months before each year
+	jewishMonthsPerYear = [19]int{12, 12, 13, 12, 12, 13, 12, 13, 12, 12, 13, 12, 12, 13, 12, 12, 13, 12, 13}
+	jewishYearOffset    = [19]int{0, 12, 24, 37, 49, 61, 74, 86, 99, 111, 123, 136, 148, 160, 173, 185, 197, 210, 222}
+)
+
+// GregorianToJd — Converts a Gregorian date to Julian Day Count
+// GregorianToJd(10, 11, 1970) == 2440871
+func GregorianToJd(month, day, year int) int {
+	if year == 0 || year < -4714 || month <= 0 || month > 12 || day <= 0 || day > 31 {
+		return 0
+	}
+	// before November 25, 4714 BC
+	if year == -4714 && (month < 11 || month == 11 && day < 25) {
+		return 0
+	}
+
+	if year < 0 {
+		year += 4801
+	} else {
+		year += 4800
+	}
+	if month > 2 {
+		month -= 3
+	} else {
+		month += 9
+		year--
+	}
+
+	return (year/100)*daysPer400Years/4 + (year%100)*daysPer4Years/4 + (month*daysPer5Months+2)/5 + day - gregorianSdnOffset
+}
+
+// sdnToGregorian returns the Gregorian date of a day number, zeros when it is out of range
+func sdnToGregorian(sdn int) (year, month, day int) {
+	if sdn <= 0 {
+		return 0, 0, 0
+	}
+
+	temp := (sdn+gregorianSdnOffset)*4 - 1
+	century := temp / daysPer400Years
+
+	temp = temp%daysPer400Years/4*4 + 3
+	year = century*100 + temp/daysPer4Years
+	dayOfYear := temp%daysPer4Years/4 + 1
+
+	return sdnYearMonthDay(year, dayOfYear)
+}
+
+// sdnYearMonthDay finishes the Gregorian and Julian conversions from a year starting in March
+func sdnYearMonthDay(year, dayOfYear int) (int, int, int) {
+	temp := dayOfYear*5 - 3
+	month := temp / daysPer5Months
+	day := temp%daysPer5Months/5 + 1
+
+	if month < 10 {
+		month += 3
+	} else {
+		year++
+		month -= 9
+	}
+
+	year -= 4800
+	if year <= 0 {
+		year--
+	}
+	return year, month, day
+}
+
+// JdToGregorian — Converts Julian Day Count to Gregorian date, formatted as "month/day/year"
+// JdToGregorian(2440871) == "10/11/1970"
+func JdToGregorian(julianDay int) string {
+	return calendarDate(sdnToGregorian(julianDay))
+}
+
+// JulianToJd — Converts a Julian Calendar date to Julian Day Count
+// JulianToJd(10, 5, 1582) == 2299161
+func JulianToJd(month, day, year int) int {
+	if year == 0 || year < -4713 || month <= 0 || month > 12 || day <= 0 || day > 31 {
+		return 0
+	}
+	// before January 2, 4713 BC
+	if year == -4713 && month == 1 && day == 1 {
+		return 0
+	}
+
+	if year < 0 {
+		year += 4801
+	} else {
+		year += 4800
+	}
+	if month > 2 {
+		month -= 3
+	} else {
+		month += 9
+		year--
+	}
+
+	return year*daysPer4Years/4 + (month*daysPer5Months+2)/5 + day - julianSdnOffset
+}
+
+// sdnToJulian returns the Julian calendar date of a day number, zeros when it is out of range
+func sdnToJulian(sdn int) (year, month, day int) {
+	if sdn <= 0 {
+		return 0, 0, 0
+	}
+
+	temp := sdn*4 + julianSdnOffset*4 - 1
+	return sdnYearMonthDay(temp/daysPer4Years, temp%daysPer4Years/4+1)
+}
+
+// JdToJulian — Converts a Julian Day Count to a Julian Calendar date, formatted as "month/day/year"
+// JdToJulian(2299161) == "10/5/1582"
+func JdToJulian(julianDay int) string {
+	return calendarDate(sdnToJulian(julianDay))
+}
+
+// FrenchToJd — Converts a date from the French Republican Calendar to a Julian Day Count
+// Only years 1 to 14 (September 22, 1792 to September 22, 1806) are supported.
+// FrenchToJd(1, 1, 1) == 2375840
+func FrenchToJd(month, day, year int) int {
+	if year < 1 || year > 14 || month < 1 || month > 13 || day < 1 || day > 30 {
+		return 0
+	}
+
+	return year*daysPer4Years/4 + (month-1)*30 + day + frenchSdnOffset
+}
+
+// sdnToFrench returns the French Republican date of a day number, zeros when it is out of range
+func sdnToFrench(sdn int) (year, month, day int) {
+	if sdn < frenchFirstValid || sdn > frenchLastValid {
+		return 0, 0, 0
+	}
+
+	temp := (sdn-frenchSdnOffset)*4 - 1
+	dayOfYear := temp % daysPer4Years / 4
+	return temp / daysPer4Years, dayOfYear/30 + 1, dayOfYear%30 + 1
+}
+
+// JdToFrench — Converts a Julian Day Count to the French Republican Calendar, formatted as "month/day/year"
+func JdToFrench(julianDay int) string {
+	return calendarDate(sdnToFrench(julianDay))
+}
+
+// molad returns the day and halakim (1/1080 hour) of the first new moon of a metonic cycle
+func molad(metonicCycle int) (day, halakim int) {
+	h := newMoonOfCreation + metonicCycle*halakimPerMetonicCycle
+	return h / halakimPerDay, h % halakimPerDay
+}
+
+// addLunarMonths adds months of the mean lunar cycle to a molad
+func addLunarMonths(day, halakim, months int) (int, int) {
+	halakim += halakimPerLunarCycle * months
+	return day + halakim/halakimPerDay, halakim % halakimPerDay
+}
+
+// tishri1 returns the day of Rosh Hashanah given the molad of Tishri, applying the postponement rules
+func tishri1(metonicYear, moladDay, moladHalakim int) int {
+	tishri := moladDay
+	dow := tishri % 7
+	leapYear := jewishMonthsPerYear[metonicYear] == 13
+	lastWasLeapYear := jewishMonthsPerYear[(metonicYear+18)%19] == 13
+
+	// rules 2, 3 and 4
+	if moladHalakim >= 18*halakimPerHour ||
+		!leapYear && dow == 2 && moladHalakim >= 9*halakimPerHour+204 ||
+		lastWasLeapYear && dow == 1 && moladHalakim >= 15*halakimPerHour+589 {
+		tishri++
+		dow = (dow + 1) % 7
+	}
+	// rule 1 last, it can delay another day: never on Sunday, Wednesday or Friday
+	if dow == 3 || dow == 5 || dow == 0 {
+		tishri++
+	}
+	return tishri
+}
+
+// findTishriMolad returns the metonic cycle and year of the molad of Tishri closest to day
+func findTishriMolad(inputDay int) (metonicCycle, metonicYear, day, halakim int) {
+	// an under estimate at most, there are 6939.6896 days in a metonic cycle
+	metonicCycle = (inputDay + 310) / 6940
+	day, halakim = molad(metonicCycle)
+	for day < inputDay-6940+310 {
+		metonicCycle++
+		halakim += halakimPerMetonicCycle
+		day, halakim = day+halakim/halakimPerDay, halakim%halakimPerDay
+	}
+
+	for metonicYear = 0; metonicYear < 18; metonicYear++ {
+		if day > inputDay-74 {
+			break
+		}
+		day, halakim = addLunarMonths(day, halakim, jewishMonthsPerYear[metonicYear])
+	}
+	return metonicCycle, metonicYear, day, halakim
+}
+
+// findStartOfYear returns the metonic year, molad of Tishri and Tishri 1 of a Jewish year
+func findStartOfYear(year int) (metonicYear, moladDay, moladHalakim, tishri int) {
+	metonicYear = (year - 1) % 19
+	moladDay, moladHalakim = molad((year - 1) / 19)
+	moladDay, moladHalakim = addLunarMonths(moladDay, moladHalakim, jewishYearOffset[metonicYear])
+	return metonicYear, moladDay, moladHalakim, tishri1(metonicYear, moladDay, moladHalakim)
+}
+
+// JewishToJd — Converts a date in the Jewish Calendar to Julian Day Count
+// Months are numbered from 1 for Tishri to 13 for Elul; month 6 is Adar I and month 7 is Adar II in
+// leap years, ordinary years have only Adar as month 7.
+// JewishToJd(1, 1, 5779) == 2458372
+func JewishToJd(month, day, year int) int {
+	if year <= 0 || day <= 0 || day > 30 {
+		return 0
+	}
+
+	var sdn int
+	switch month {
+	case 1, 2:
+		_, _, _, tishri := findStartOfYear(year)
+		sdn = tishri + day - 1
+		if month == 2 {
+			sdn += 30
+		}
+	case 3:
+		// Kislev follows Heshvan, which has 29 or 30 days depending on the length of the year
+		metonicYear, moladDay, moladHalakim, tishri := findStartOfYear(year)
+		moladDay, moladHalakim = addLunarMonths(moladDay, moladHalakim, jewishMonthsPerYear[metonicYear])
+		yearLength := tishri1((metonicYear+1)%19, moladDay, moladHalakim) - tishri
+		sdn = tishri + day + 58
+		if yearLength == 355 || yearLength == 385 {
+			sdn++
+		}
+	case 4, 5, 6:
+		_, _, _, tishriAfter := findStartOfYear(year + 1)
+		adar := 59
+		if jewishMonthsPerYear[(year-1)%19] == 12 {
+			adar = 29
+		}
+		sdn = tishriAfter + day - adar - [...]int{4: 237, 5: 208, 6: 178}[month]
+	case 7, 8, 9, 10, 11, 12, 13:
+		_, _, _, tishriAfter := findStartOfYear(year + 1)
+		sdn = tishriAfter + day - [...]int{7: 207, 8: 178, 9: 148, 10: 119, 11: 89, 12: 60, 13: 30}[month]
+	default:
+		return 0
+	}
+
+	return sdn + jewishSdnOffset
+}
+
+// sdnToJewish returns the Jewish date of a day number, zeros when it is out of range
+func sdnToJewish(sdn int) (year, month, day int) {
+	if sdn <= jewishSdnOffset || sdn > jewishSdnMax {
+		return 0, 0, 0
+	}
+	inputDay := sdn - jewishSdnOffset
+
+	metonicCycle, metonicYear, moladDay, moladHalakim := findTishriMolad(inputDay)
+	tishri := tishri1(metonicYear, moladDay, moladHalakim)
+
+	var tishriAfter int
+	if inputDay >= tishri {
+		// the molad found starts this year
+		year = metonicCycle*19 + metonicYear + 1
+		if inputDay < tishri+30 {
+			return year, 1, inputDay - tishri + 1
+		}
+		if inputDay < tishri+59 {
+			return year, 2, inputDay - tishri - 29
+		}
+
+		// Heshvan and Kislev depend on the length of the year
+		moladDay, moladHalakim = addLunarMonths(moladDay, moladHalakim, jewishMonthsPerYear[metonicYear])
+		tishriAfter = tishri1((metonicYear+1)%19, moladDay, moladHalakim)
+	} else {
+		// the molad found starts the next year, count back from it
+		year = metonicCycle*19 + metonicYear
+		switch {
+		case inputDay > tishri-30:
+			return year, 13, inputDay - tishri + 30
+		case inputDay > tishri-60:
+			return year, 12, inputDay - tishri + 60
+		case inputDay > tishri-89:
+			return year, 11, inputDay - tishri + 89
+		case inputDay > tishri-119:
+			return year, 10, inputDay - tishri + 119
+		case inputDay > tishri-148:
+			return year, 9, inputDay - tishri + 148
+		case inputDay >= tishri-177:
+			return year, 8, inputDay - tishri + 178
+		}
+
+		month, day = 7, inputDay-tishri+207
+		if day > 0 {
+			return year, month, day
+		}
+		if jewishMonthsPerYear[(year-1)%19] == 13 {
+			month, day = month-1, day+30
+			if day > 0 {
+				return year, month, day
+			}
+			month, day = month-1, day+30
+		} else {
+			month, day = month-2, day+30
+		}
+		if day > 0 {
+			return year, month, day
+		}
+		month, day = month-1, day+29
+		if day > 0 {
+			return year, month, day
+		}
+
+		tishriAfter = tishri
+		_, metonicYear, moladDay, moladHalakim = findTishriMolad(moladDay - 365)
+		tishri = tishri1(metonicYear, moladDay, moladHalakim)
+	}
+
+	yearLength := tishriAfter - tishri
+	day = inputDay - tishri - 29
+	heshvan := 29
+	if yearLength == 355 || yearLength == 385 {
+		heshvan = 30
+	}
+	if day <= heshvan {
+		return year, 2, day
+	}
+	return year, 3, day - heshvan
+}
+
+// JdToJewish — Converts a Julian Day Count to the Jewish Calendar, formatted as "month/day/year"
+// PHP's Hebrew output is not supported.
+// JdToJewish(2458372) == "1/1/5779"
+func JdToJewish(julianDay int) string {
+	return calendarDate(sdnToJewish(julianDay))
+}
+
+// calendarDate formats a date the way PHP's jdto* functions do
+func calendarDate(year, month, day int) string {
+	return strconv.Itoa(month) + "/" + strconv.Itoa(day) + "/" + strconv.Itoa(year)
+}
+
+// UnixToJd — Convert Unix timestamp to Julian Day
+// The day is taken in UTC. UnixToJd(1000000000) == 2452162
+func UnixToJd(timestamp int64) int {
+	days := timestamp / 86400
+	if timestamp%86400 < 0 {
+		days--
+	}
+	return int(days) + unixEpochJd
+}
+
+// JdToUnix — Convert Julian Day to Unix timestamp, the start of the day in UTC
+// JdToUnix(2440588) == 0
+func JdToUnix(julianDay int) int64 {
+	return int64(julianDay-unixEpochJd) * 86400
+}
+
+// JdDayOfWeek — Returns the day of the week
+// mode is CalDowDayNo for an int, CalDowLong or CalDowShort for a string.
+// JdDayOfWeek(2440588, CalDowLong) == "Thursday"
+func JdDayOfWeek(julianDay int, mode int) interface{} {
+	dow := (julianDay + 1) % 7
+	if dow < 0 {
+		dow += 7
+	}
+
+	switch mode {
+	case CalDowLong:
+		return time.Weekday(dow).String()
+	case CalDowShort:
+		return time.Weekday(dow).String()[:3]
+	}
+	return dow
+}
+
+// JdMonthName — Returns a month name
+// mode is one of the CalMonth* constants; out of range days give an empty string.
+// JdMonthName(2440588, CalMonthGregorianLong) == "January"
+func JdMonthName(julianDay int, mode int) string {
+	switch mode {
+	case CalMonthGregorianLong:
+		_, month, _ := sdnToGregorian(julianDay)
+		return monthNamesLong[month]
+	case CalMonthJulianShort:
+		_, month, _ := sdnToJulian(julianDay)
+		return monthNamesShort[month]
+	case CalMonthJulianLong:
+		_, month, _ := sdnToJulian(julianDay)
+		return monthNamesLong[month]
+	case CalMonthJewish:
+		year, month, _ := sdnToJewish(julianDay)
+		if year <= 0 {
+			return ""
+		}
+		if jewishMonthsPerYear[(year-1)%19] == 13 {
+			return jewishMonthNamesLeap[month]
+		}
+		return jewishMonthNames[month]
+	case CalMonthFrench:
+		_, month, _ := sdnToFrench(julianDay)
+		return frenchMonthNames[month]
+	}
+
+	_, month, _ := sdnToGregorian(julianDay)
+	return monthNamesShort[month]
+}
+
+// CalDaysInMonth — Return the number of days in a month for a given year and calendar
+// CalDaysInMonth(CalGregorian, 2, 2020) == 29
+func CalDaysInMonth(calendar, month, year int) (int, error) {
+	var toJd func(month, day, year int) int
+	switch calendar {
+	case CalGregorian:
+		toJd = GregorianToJd
+	case CalJulian:
+		toJd = JulianToJd
+	case CalJewish:
+		toJd = JewishToJd
+	case CalFrench:
+		toJd = FrenchToJd
+	default:
+		return 0, fmt.Errorf("invalid calendar ID %d", calendar)
+	}
+
+	start := toJd(month, 1, year)
+	if start == 0 {
+		return 0, fmt.Errorf("invalid date")
+	}
+
+	next := toJd(month+1, 1, year)
+	if next == 0 {
+		// the year after 1 BC is 1 AD, and the French calendar ends on 13/5/14
+		switch {
+		case year == -1:
+			next = toJd(1, 1, 1)
+		case calendar == CalFrench && year == 14:
+			next = frenchLastValid + 1
+		default:
+			next = toJd(1, 1, year+1)
+		}
+	}
+	return next - start, nil
+}
+
+// EasterDays — Get number of days after March 21 on which Easter falls for a given year
+// mode is one of the CalEaster* constants, by default the Julian calendar is used before 1753.
+// EasterDays(1999, CalEasterDefault) == 14
+func EasterDays(year int, mode int) int {
+	golden := year%19 + 1
+
+	var dom, pfm int
+	if year <= 1582 && mode != CalEasterAlwaysGregorian ||
+		year >= 1583 && year <= 1752 && mode != CalEasterRoman && mode != CalEasterAlwaysGregorian ||
+		mode == CalEasterAlwaysJulian {
+		// the dominical number finds a Sunday, pfm is the uncorrected Paschal full moon
+		dom = (year + year/4 + 5) % 7
+		pfm = (3 - 11*golden - 7) % 30
+	} else {
+		// with the solar and lunar corrections of the Gregorian calendar
+		dom = (year + year/4 - year/100 + year/400) % 7
+		solar := (year-1600)/100 - (year-1600)/400
+		lunar := (year - 1400) / 100 * 8 / 25
+		pfm = (3 - 11*golden + solar - lunar) % 30
+	}
+	if dom < 0 {
+		dom += 7
+	}
+	if pfm < 0 {
+		pfm += 30
+	}
+
+	// the corrected Paschal full moon, in days after March 21
+	if pfm == 29 || pfm == 28 && golden > 11 {
+		pfm--
+	}
+
+	return pfm + ((4-pfm-dom)%7+7)%7 + 1
+}
+
+// EasterDate — Get Unix timestamp for midnight on Easter of a given year
+// Midnight is taken in the package default timezone, see SetLocation.
+// EasterDate(2000, CalEasterDefault) == Mktime(0, 0, 0, 4, 23, 2000)
+func EasterDate(year int, mode int) int64 {
+	month, day := easterMonthDay(year, mode)
+	return time.Date(year, month, day, 0, 0, 0, 0, getLocation()).Unix()
+}
+
+// easterMonthDay returns the month and day of Easter Sunday
+func easterMonthDay(year int, mode int) (time.Month, int) {
+	return time.March, 21 + EasterDays(year, mode)
+}
+
+// easterSunday returns the date of Easter Sunday in the proleptic Gregorian calendar
+func easterSunday(year int) (time.Month, int) {
+	month, day := easterMonthDay(year, CalEasterAlwaysGregorian)
+	if day > 31 {
+		return time.April, day - 31
+	}
+	return month, day
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarConversions(t *testing.T) {
+	equal(t, 2440871, GregorianToJd(10, 11, 1970))
+	equal(t, 2451545, GregorianToJd(1, 1, 2000))
+	equal(t, 2299161, GregorianToJd(10, 15, 1582))
+	equal(t, 1, GregorianToJd(11, 25, -4714))
+	for _, d := range [][3]int{{0, 0, 0}, {11, 24, -4714}, {1, 1, 0}, {13, 1, 2000}, {1, 32, 2000}} {
+		equal(t, 0, GregorianToJd(d[0], d[1], d[2]))
+	}
+	equal(t, "10/11/1970", JdToGregorian(2440871))
+	equal(t, "11/25/-4714", JdToGregorian(1))
+	equal(t, "12/31/-1", JdToGregorian(GregorianToJd(1, 1, 1)-1))
+	equal(t, "0/0/0", JdToGregorian(0))
+
+	equal(t, 2440884, JulianToJd(10, 11, 1970))
+	equal(t, 2299161, JulianToJd(10, 5, 1582))
+	equal(t, 1, JulianToJd(1, 2, -4713))
+	equal(t, 0, JulianToJd(1, 1, -4713))
+	equal(t, "10/5/1582", JdToJulian(2299161))
+	equal(t, "1/2/-4713", JdToJulian(1))
+	equal(t, "0/0/0", JdToJulian(-1))
+
+	equal(t, 2375840, FrenchToJd(1, 1, 1))
+	equal(t, 2380952, FrenchToJd(13, 5, 14))
+	equal(t, 0, FrenchToJd(1, 1, 15))
+	equal(t, 0, FrenchToJd(14, 1, 1))
+	equal(t, "1/1/1", JdToFrench(2375840))
+	equal(t, "13/5/14", JdToFrench(2380952))
+	equal(t, "0/0/0", JdToFrench(2380953))
+	equal(t, "9/22/1792", JdToGregorian(FrenchToJd(1, 1, 1)))
+
+	// Jewish holidays and their Gregorian dates
+	jewish := map[[3]int]string{
+		{1, 1, 5779}:   "9/10/2018",  // Rosh Hashanah
+		{1, 10, 5780}:  "10/9/2019",  // Yom Kippur
+		{3, 25, 5778}:  "12/13/2017", // Hanukkah, Heshvan of 29 days
+		{3, 25, 5780}:  "12/23/2019", // Hanukkah, Heshvan of 30 days
+		{7, 14, 5778}:  "3/1/2018",   // Purim in an ordinary year
+		{7, 14, 5779}:  "3/21/2019",  // Purim in Adar II of a leap year
+		{6, 1, 5779}:   "2/6/2019",   // Adar I
+		{8, 15, 5778}:  "3/31/2018",  // Passover
+		{13, 29, 5778}: "9/9/2018",   // the last day of the year
+	}
+	for d, gregorian := range jewish {
+		jd := JewishToJd(d[0], d[1], d[2])
+		equal(t, gregorian, JdToGregorian(jd))
+		equal(t, calendarDate(d[2], d[0], d[1]), JdToJewish(jd))
+	}
+	equal(t, 0, JewishToJd(14, 1, 5779))
+	equal(t, 0, JewishToJd(1, 1, 0))
+	equal(t, "0/0/0", JdToJewish(347997))
+	equal(t, "1/1/1", JdToJewish(347998))
+
+	// every day of a few Jewish years round trips
+	for jd := GregorianToJd(9, 1, 2017); jd < GregorianToJd(10, 1, 2021); jd++ {
+		y, m, d := sdnToJewish(jd)
+		equal(t, jd, JewishToJd(m, d, y))
+		gy, gm, gd := sdnToGregorian(jd)
+		equal(t, jd, GregorianToJd(gm, gd, gy))
+		jy, jm, jdd := sdnToJulian(jd)
+		equal(t, jd, JulianToJd(jm, jdd, jy))
+	}
+
+	equal(t, 2452162, UnixToJd(1000000000))
+	equal(t, 2440588, UnixToJd(0))
+	equal(t, 2440587, UnixToJd(-1))
+	equal(t, int64(0), JdToUnix(2440588))
+	equal(t, int64(999993600), JdToUnix(2452162))
+
+	equal(t, 4, JdDayOfWeek(2440588, CalDowDayNo))
+	equal(t, "Thursday", JdDayOfWeek(2440588, CalDowLong))
+	equal(t, "Thu", JdDayOfWeek(2440588, CalDowShort))
+	equal(t, 0, JdDayOfWeek(GregorianToJd(4, 29, 2018), CalDowDayNo))
+
+	equal(t, "Jan", JdMonthName(2440588, CalMonthGregorianShort))
+	equal(t, "January", JdMonthName(2440588, CalMonthGregorianLong))
+	equal(t, "Dec", JdMonthName(2440588, CalMonthJulianShort))
+	equal(t, "December", JdMonthName(2440588, CalMonthJulianLong))
+	equal(t, "Tevet", JdMonthName(2440588, CalMonthJewish))
+	equal(t, "Adar", JdMonthName(JewishToJd(7, 1, 5778), CalMonthJewish))
+	equal(t, "Adar I", JdMonthName(JewishToJd(6, 1, 5779), CalMonthJewish))
+	equal(t, "Adar II", JdMonthName(JewishToJd(7, 1, 5779), CalMonthJewish))
+	equal(t, "Vendemiaire", JdMonthName(2375840, CalMonthFrench))
+	equal(t, "", JdMonthName(2440588, CalMonthFrench))
+	equal(t, "Jan", JdMonthName(2440588, 99))
+
+	days := map[[3]int]int{
+		{CalGregorian, 8, 2003}: 31,
+		{CalGregorian, 2, 2003}: 28,
+		{CalGregorian, 2, 2004}: 29,
+		{CalGregorian, 2, 1900}: 28,
+		{CalGregorian, 12, -1}:  31,
+		{CalJulian, 2, 1900}:    29,
+		{CalJewish, 2, 5778}:    29,
+		{CalJewish, 2, 5779}:    30,
+		{CalJewish, 6, 5779}:    30,
+		{CalJewish, 13, 5779}:   29,
+		{CalFrench, 13, 3}:      6,
+		{CalFrench, 13, 14}:     5,
+	}
+	for c, expected := range days {
+		n, err := CalDaysInMonth(c[0], c[1], c[2])
+		equal(t, nil, err)
+		equal(t, expected, n)
+	}
+	_, err := CalDaysInMonth(CalGregorian, 13, 2018)
+	unequal(t, nil, err)
+	_, err = CalDaysInMonth(4, 1, 2018)
+	unequal(t, nil, err)
+
+	equal(t, 14, EasterDays(1999, CalEasterDefault))
+	equal(t, 32, EasterDays(1492, CalEasterDefault))
+	equal(t, 2, EasterDays(1913, CalEasterDefault))
+	equal(t, 10, EasterDays(1700, CalEasterDefault))
+	equal(t, 21, EasterDays(1700, CalEasterRoman))
+	equal(t, 21, EasterDays(1700, CalEasterAlwaysGregorian))
+	equal(t, 5, EasterDays(2018, CalEasterAlwaysJulian))
+
+	defer SetLocation(SetLocation(time.UTC))
+	equal(t, int64(956448000), EasterDate(2000, CalEasterDefault))
+	equal(t, GmMktime(0, 0, 0, 4, 1, 2018), EasterDate(2018, CalEasterDefault))
+	equal(t, GmMktime(0, 0, 0, 3, 23, 1913), EasterDate(1913, CalEasterDefault))
+	amsterdam, _ := time.LoadLocation("Europe/Amsterdam")
+	SetLocation(amsterdam)
+	equal(t, MktimeIn(0, 0, 0, 4, 21, 2019, amsterdam), EasterDate(2019, CalEasterDefault))
+}
@@ -0,0 +1,184 @@
+package utils
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock — A source of the current time and of timers
+// Time, TimeIn, Sleep, Usleep, Microtime, Hrtime, Uniqid, Parse, DateCreateFromFormat and the Scheduler
+// read the package clock, see SetClock.
+// Timer is After for callers that may stop waiting early: they call stop once they no longer wait.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	Timer(d time.Duration) (c <-chan time.Time, stop func())
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) Timer(d time.Duration) (<-chan time.Time, func()) {
+	t := time.NewTimer(d)
+	return t.C, func() { t.Stop() }
+}
+
+// NewRealClock — Create a clock reading the system time
+func NewRealClock() Clock {
+	return realClock{}
+}
+
+var (
+	clockMu    sync.RWMutex
+	clock      Clock = realClock{}
+	clockStart       = time.Now()
+)
+
+// SetClock — Replace the package clock and return the previous one
+// Passing nil restores the system clock.
+// Usage in tests:
+// defer SetClock(SetClock(NewFakeClock(time.Date(2018, 4, 27, 10, 23, 14, 0, time.UTC))))
+func SetClock(c Clock) Clock {
+	if c == nil {
+		c = realClock{}
+	}
+
+	clockMu.Lock()
+	defer clockMu.Unlock()
+
+	prev := clock
+	clock, clockStart = c, c.Now()
+	return prev
+}
+
+// getClock returns the package clock
+func getClock() Clock {
+	clockMu.RLock()
+	defer clockMu.RUnlock()
+	return clock
+}
+
+// FakeClock — A clock that only moves when told to
+// Timers fire when Advance or Set moves the time past them. It is safe for concurrent use.
+// c := NewFakeClock(start)
+// go Sleep(10)
+// c.BlockUntil(1)
+// c.Advance(10 * time.Second)
+type FakeClock struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+type fakeWaiter struct {
+	until time.Time
+	ch    chan time.Time
+}
+
+// NewFakeClock — Create a fake clock showing t
+func NewFakeClock(t time.Time) *FakeClock {
+	c := &FakeClock{now: t}
+	c.cond = sync.NewCond(&c.mu)
+	return c
+}
+
+// Now — Return the time shown by the clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After — Return a channel receiving the time once the clock has moved d forward
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	ch, _ := c.Timer(d)
+	return ch
+}
+
+// Timer — Return a channel receiving the time once the clock has moved d forward, and a function
+// removing the timer from the waiters when it is no longer waited for
+func (c *FakeClock) Timer(d time.Duration) (<-chan time.Time, func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w := &fakeWaiter{c.now.Add(d), make(chan time.Time, 1)}
+	if d <= 0 {
+		w.ch <- c.now
+		return w.ch, func() {}
+	}
+
+	c.waiters = append(c.waiters, w)
+	c.cond.Broadcast()
+	return w.ch, func() { c.stop(w) }
+}
+
+// stop removes a waiter that has not fired yet
+func (c *FakeClock) stop(w *fakeWaiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, waiter := range c.waiters {
+		if waiter == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			return
+		}
+	}
+}
+
+// Advance — Move the clock d forward, firing the timers due on the way
+func (c *FakeClock) Advance(d time.Duration) {
+	if d < 0 {
+		panic("duration: cannot be negative")
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(c.now.Add(d))
+}
+
+// Set — Move the clock to t, firing the timers due by then
+// Moving it back fires nothing.
+func (c *FakeClock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.set(t)
+}
+
+// set moves the clock, c.mu must be held
+func (c *FakeClock) set(t time.Time) {
+	c.now = t
+
+	sort.SliceStable(c.waiters, func(i, j int) bool { return c.waiters[i].until.Before(c.waiters[j].until) })
+	n := 0
+	for ; n < len(c.waiters) && !c.waiters[n].until.After(t); n++ {
+		c.waiters[n].ch <- t
+	}
+	c.waiters = c.waiters[n:]
+}
+
+// Waiters — Return the number of timers waiting for the clock to move
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil — Wait until at least n timers wait for the clock to move
+// It lets a test advance the clock only once the code under test sleeps.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.waiters) < n {
+		c.cond.Wait()
+	}
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	start := time.Date(2018, 4, 27, 10, 23, 14, 654321000, time.UTC)
+	fake := NewFakeClock(start)
+	defer SetClock(SetClock(fake))
+
+	equal(t, int64(1524824594), Time())
+	equal(t, "17:23:14", TimeIn(time.FixedZone("WIB", 7*3600)).Format("15:04:05"))
+	equal(t, "0.65432100 1524824594", Microtime(false))
+	equal(t, 1524824594.654321, Microtime(true))
+	equal(t, int64(0), Hrtime(true))
+	uniqidMu.Lock()
+	uniqidLast = 0
+	uniqidMu.Unlock()
+	equal(t, "5ae2fa129fbf1", Uniqid("", false))
+	equal(t, "5ae2fa129fbf2", Uniqid("", false))
+
+	// missing fields come from the clock
+	defer SetLocation(SetLocation(time.UTC))
+	tm, _ := DateCreateFromFormat("H:i", "08:30")
+	equal(t, "2018-04-27 08:30:00", tm.Format("2006-01-02 15:04:05"))
+	tm, _ = Parse("12-25")
+	equal(t, "2018-12-25", tm.Format("2006-01-02"))
+
+	done := make(chan struct{})
+	go func() {
+		Sleep(10)
+		Usleep(500)
+		close(done)
+	}()
+	fake.BlockUntil(1)
+	fake.Advance(9 * time.Second)
+	equal(t, 1, fake.Waiters())
+	fake.Advance(time.Second)
+	fake.BlockUntil(1)
+	fake.Advance(500 * time.Microsecond)
+	<-done
+	equal(t, start.Add(10*time.Second+500*time.Microsecond), fake.Now())
+	equal(t, int64(10000500000), Hrtime(true))
+	equal(t, []int64{10, 500000}, Hrtime(false))
+
+	// timers fire in order, moving back fires nothing
+	a, b := fake.After(2*time.Second), fake.After(time.Second)
+	fake.Set(start)
+	equal(t, 2, fake.Waiters())
+	fake.Set(fake.Now().Add(time.Minute))
+	equal(t, start.Add(time.Minute), <-a)
+	equal(t, start.Add(time.Minute), <-b)
+	equal(t, start.Add(time.Minute), <-fake.After(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error)
+	go func() { errs <- SleepContext(ctx, 60) }()
+	fake.BlockUntil(1)
+	cancel()
+	equal(t, context.Canceled, <-errs)
+	equal(t, context.Canceled, UsleepContext(ctx, 1))
+	equal(t, 0, fake.Waiters()) // the cancelled sleep no longer waits on the clock
+
+	go func() { errs <- UsleepContext(context.Background(), 100) }()
+	fake.BlockUntil(1)
+	fake.Advance(100 * time.Microsecond)
+	equal(t, nil, <-errs)
+
+	timer, stop := fake.Timer(time.Second)
+	equal(t, 1, fake.Waiters())
+	stop()
+	stop()
+	equal(t, 0, fake.Waiters())
+	fake.Advance(time.Second)
+	equal(t, 0, len(timer))
+
+	equal(t, false, TimeSleepUntil(float64(Time())-1))
+	slept := make(chan bool)
+	go func() { slept <- TimeSleepUntil(Microtime(true).(float64) + 1.5) }()
+	fake.BlockUntil(1)
+	fake.Advance(1500 * time.Millisecond)
+	equal(t, true, <-slept)
+
+	// the real clock is restored by nil
+	SetClock(nil)
+	gt(t, float64(Hrtime(true).(int64)), -1)
+	gte(t, float64(Time()), float64(time.Now().Unix()))
+	equal(t, nil, SleepContext(context.Background(), 0))
+	SetClock(fake)
+}
@@ -0,0 +1,556 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Cron — A parsed cron schedule
+// Times are matched on the wall clock of the schedule's timezone, or of the time passed to Next
+// when the expression has none. A wall time skipped when clocks go forward does not run that
+// day, and a wall time repeated when they go back runs once.
+type Cron struct {
+	spec string
+	loc  *time.Location
+
+	second, minute, hour, dom, month, dow uint64 // a bit per allowed value
+
+	domStar, dowStar bool
+	lastDay          []int // "L" and "L-3": days before the last day of the month
+	lastWeekday      bool  // "LW": the last weekday of the month
+	nearestWeekday   []int // "15W": the weekday nearest to the day, within the month
+	dowLast          uint8 // "5L": a bit per weekday falling in the last week of the month
+	dowNth           [7]uint8
+}
+
+// cronField describes a field of a cron expression
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var (
+	cronSecond = cronField{"second", 0, 59, nil}
+	cronMinute = cronField{"minute", 0, 59, nil}
+	cronHour   = cronField{"hour", 0, 23, nil}
+	cronDom    = cronField{"day of month", 1, 31, nil}
+	cronMonth  = cronField{"month", 1, 12, map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	cronDow = cronField{"day of week", 0, 7, map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	cronMacros = map[string]string{
+		"@yearly":   "0 0 1 1 *",
+		"@annually": "0 0 1 1 *",
+		"@monthly":  "0 0 1 * *",
+		"@weekly":   "0 0 * * 0",
+		"@daily":    "0 0 * * *",
+		"@midnight": "0 0 * * *",
+		"@hourly":   "0 * * * *",
+	}
+)
+
+// ParseCron — Parse a standard cron expression
+// It has 5 fields, minute hour day-of-month month day-of-week, or 6 with seconds first.
+// Fields take *, lists, ranges, steps and English names; ? is * for the day fields and 7 is Sunday.
+// The day of month also takes L (last day), L-3, LW (last weekday) and 15W (nearest weekday), the day
+// of week 5L (last Friday) and 5#3 (third Friday). When both day fields are restricted, either one
+// matching is enough; a field starting with * only restricts the days further. The macros @yearly,
+// @annually, @monthly, @weekly, @daily, @midnight and @hourly are accepted, and a CRON_TZ= or TZ=
+// prefix selects the timezone.
+// ParseCron("30 2 * * 1-5")
+// ParseCron("CRON_TZ=Europe/Amsterdam 0 0 9 L * ?")
+func ParseCron(spec string) (*Cron, error) {
+	c := &Cron{spec: spec}
+
+	fields := strings.Fields(spec)
+	if len(fields) > 0 && (strings.HasPrefix(fields[0], "CRON_TZ=") || strings.HasPrefix(fields[0], "TZ=")) {
+		loc, err := time.LoadLocation(fields[0][strings.IndexByte(fields[0], '=')+1:])
+		if err != nil {
+			return nil, fmt.Errorf("cron: %v", err)
+		}
+		c.loc, fields = loc, fields[1:]
+	}
+
+	if len(fields) == 1 && strings.HasPrefix(fields[0], "@") {
+		macro, ok := cronMacros[strings.ToLower(fields[0])]
+		if !ok {
+			return nil, fmt.Errorf("cron: unknown macro %q", fields[0])
+		}
+		fields = strings.Fields(macro)
+	}
+
+	switch len(fields) {
+	case 5:
+		fields = append([]string{"0"}, fields...)
+	case 6:
+	default:
+		return nil, fmt.Errorf("cron: expected 5 or 6 fields, found %d in %q", len(fields), spec)
+	}
+
+	var err error
+	if c.second, err = cronSecond.parse(fields[0]); err != nil {
+		return nil, err
+	}
+	if c.minute, err = cronMinute.parse(fields[1]); err != nil {
+		return nil, err
+	}
+	if c.hour, err = cronHour.parse(fields[2]); err != nil {
+		return nil, err
+	}
+	if c.month, err = cronMonth.parse(fields[4]); err != nil {
+		return nil, err
+	}
+	if err = c.parseDom(fields[3]); err != nil {
+		return nil, err
+	}
+	if err = c.parseDow(fields[5]); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// parse returns the bits of the values allowed by a field made of *, lists, ranges and steps
+func (f cronField) parse(field string) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(field, ",") {
+		bits, err := f.parseItem(item)
+		if err != nil {
+			return 0, err
+		}
+		set |= bits
+	}
+	return set, nil
+}
+
+// parseItem parses one item of a list: *, a value or a range, each with an optional step
+func (f cronField) parseItem(item string) (uint64, error) {
+	rng, step := item, 1
+	if i := strings.IndexByte(item, '/'); i >= 0 {
+		n, err := strconv.Atoi(item[i+1:])
+		if err != nil || n < 1 {
+			return 0, fmt.Errorf("cron: invalid step in %s field %q", f.name, item)
+		}
+		rng, step = item[:i], n
+	}
+
+	lo, hi := f.min, f.max
+	switch {
+	case rng == "*" || rng == "?" && (f.name == cronDom.name || f.name == cronDow.name):
+	case strings.Contains(rng, "-"):
+		i := strings.IndexByte(rng, '-')
+		var err error
+		if lo, err = f.value(rng[:i]); err != nil {
+			return 0, err
+		}
+		if hi, err = f.value(rng[i+1:]); err != nil {
+			return 0, err
+		}
+		if lo > hi {
+			return 0, fmt.Errorf("cron: range %q in %s field is backwards", rng, f.name)
+		}
+	default:
+		var err error
+		if lo, err = f.value(rng); err != nil {
+			return 0, err
+		}
+		if rng == item {
+			hi = lo
+		}
+	}
+
+	// 7 is Sunday again, * and steps stop at Saturday
+	if f.name == cronDow.name && lo < 7 && hi == 7 && !strings.Contains(rng, "-") {
+		hi = 6
+	}
+
+	var set uint64
+	for v := lo; v <= hi; v += step {
+		set |= 1 << uint(v)
+	}
+	if f.name == cronDow.name && set&(1<<7) != 0 {
+		set = set&^(1<<7) | 1
+	}
+	return set, nil
+}
+
+// value parses a number or name in the field's range
+func (f cronField) value(s string) (int, error) {
+	v, ok := f.names[strings.ToLower(s)]
+	if !ok {
+		var err error
+		if v, err = strconv.Atoi(s); err != nil {
+			return 0, fmt.Errorf("cron: invalid value %q in %s field", s, f.name)
+		}
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("cron: value %q out of range %d-%d in %s field", s, f.min, f.max, f.name)
+	}
+	return v, nil
+}
+
+// parseDom parses the day of month field with its L and W modifiers
+func (c *Cron) parseDom(field string) error {
+	c.domStar = strings.HasPrefix(field, "*") || field == "?"
+
+	for _, item := range strings.Split(field, ",") {
+		upper := strings.ToUpper(item)
+		switch {
+		case upper == "LW":
+			c.lastWeekday = true
+		case upper == "L" || strings.HasPrefix(upper, "L-"):
+			offset := 0
+			if len(upper) > 1 {
+				n, err := strconv.Atoi(upper[2:])
+				if err != nil || n < 0 || n > 30 {
+					return fmt.Errorf("cron: invalid offset in day of month field %q", item)
+				}
+				offset = n
+			}
+			c.lastDay = append(c.lastDay, offset)
+		case strings.HasSuffix(upper, "W"):
+			day, err := cronDom.value(item[:len(item)-1])
+			if err != nil {
+				return err
+			}
+			c.nearestWeekday = append(c.nearestWeekday, day)
+		default:
+			bits, err := cronDom.parseItem(item)
+			if err != nil {
+				return err
+			}
+			c.dom |= bits
+		}
+	}
+	return nil
+}
+
+// parseDow parses the day of week field with its L and # modifiers
+func (c *Cron) parseDow(field string) error {
+	c.dowStar = strings.HasPrefix(field, "*") || field == "?"
+
+	for _, item := range strings.Split(field, ",") {
+		switch {
+		case len(item) > 1 && strings.HasSuffix(strings.ToUpper(item), "L"):
+			day, err := cronDow.value(item[:len(item)-1])
+			if err != nil {
+				return err
+			}
+			c.dowLast |= 1 << uint(day%7)
+		case strings.Contains(item, "#"):
+			i := strings.IndexByte(item, '#')
+			day, err := cronDow.value(item[:i])
+			if err != nil {
+				return err
+			}
+			n, err := strconv.Atoi(item[i+1:])
+			if err != nil || n < 1 || n > 5 {
+				return fmt.Errorf("cron: invalid occurrence in day of week field %q", item)
+			}
+			c.dowNth[day%7] |= 1 << uint(n)
+		default:
+			bits, err := cronDow.parseItem(item)
+			if err != nil {
+				return err
+			}
+			c.dow |= bits
+		}
+	}
+	return nil
+}
+
+// String — Return the expression the schedule was parsed from
+func (c *Cron) String() string {
+	return c.spec
+}
+
+// dayMatches checks a day against both day fields
+func (c *Cron) dayMatches(t time.Time) bool {
+	year, month, day := t.Date()
+	last := daysInMonth(year, month)
+	weekday := t.Weekday()
+
+	domOK := c.dom&(1<<uint(day)) != 0
+	for _, offset := range c.lastDay {
+		domOK = domOK || day == last-offset
+	}
+	if c.lastWeekday {
+		lw := last
+		switch time.Date(year, month, last, 0, 0, 0, 0, time.UTC).Weekday() {
+		case time.Saturday:
+			lw--
+		case time.Sunday:
+			lw -= 2
+		}
+		domOK = domOK || day == lw
+	}
+	for _, n := range c.nearestWeekday {
+		domOK = domOK || n <= last && day == nearestWeekday(year, month, n, last)
+	}
+
+	dowOK := c.dow&(1<<uint(weekday)) != 0 ||
+		c.dowLast&(1<<uint(weekday)) != 0 && day+7 > last ||
+		c.dowNth[weekday]&(1<<uint((day-1)/7+1)) != 0
+
+	// as in Vixie cron, a field starting with * does not widen the other one
+	if c.domStar || c.dowStar {
+		return domOK && dowOK
+	}
+	return domOK || dowOK
+}
+
+// nearestWeekday returns the Monday to Friday day nearest to day, without leaving the month
+func nearestWeekday(year int, month time.Month, day, last int) int {
+	switch time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Weekday() {
+	case time.Saturday:
+		if day == 1 {
+			return 3
+		}
+		return day - 1
+	case time.Sunday:
+		if day == last {
+			return day - 2
+		}
+		return day + 1
+	}
+	return day
+}
+
+// Next — Return the first time after the given one matching the schedule, in the same location
+// The zero time is returned when nothing matches within 10 years, as for February 30.
+func (c *Cron) Next(after time.Time) time.Time {
+	loc := c.loc
+	if loc == nil {
+		loc = after.Location()
+	}
+	local := after.In(loc)
+
+	// search the wall clock as UTC, which has no DST changes, and check each match exists in loc
+	t := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second()+1, 0, time.UTC)
+	limit := t.Year() + 10
+
+	for t.Year() <= limit {
+		switch {
+		case c.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+		case !c.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Truncate(time.Hour).Add(time.Hour)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Truncate(time.Minute).Add(time.Minute)
+		case c.second&(1<<uint(t.Second())) == 0:
+			t = t.Add(time.Second)
+		default:
+			match := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+			if match.After(after) && match.Hour() == t.Hour() && match.Minute() == t.Minute() {
+				return match.In(after.Location())
+			}
+			t = t.Add(time.Second)
+		}
+	}
+
+	return time.Time{}
+}
+
+// OverlapPolicy — What a Scheduler does when a job is due while its previous run has not finished
+type OverlapPolicy int
+
+// Overlap policies
+const (
+	OverlapSkip       OverlapPolicy = iota // the due run is dropped
+	OverlapQueue                           // the due run starts when the previous ones finish
+	OverlapConcurrent                      // the due run starts right away
+)
+
+// schedulerJob is a job registered with a Scheduler
+type schedulerJob struct {
+	id      int
+	cron    *Cron
+	policy  OverlapPolicy
+	fn      func(ctx context.Context)
+	next    time.Time
+	running int // runs started and not finished
+	queued  int // runs waiting for OverlapQueue
+}
+
+// Scheduler — Runs jobs on cron schedules until its context is cancelled
+// It reads the time from the package clock, see SetClock.
+// Jobs get the context passed to Run and each run is in its own goroutine. A panicking run is
+// recovered and reported to OnPanic, or logged when it is nil.
+// s := NewScheduler()
+// s.Add("*/5 * * * *", OverlapSkip, func(ctx context.Context) { ... })
+// s.Run(ctx)
+type Scheduler struct {
+	OnPanic func(id int, recovered interface{})
+
+	mu     sync.Mutex
+	jobs   map[int]*schedulerJob
+	nextID int
+	wake   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewScheduler — Create a scheduler without jobs
+func NewScheduler() *Scheduler {
+	return &Scheduler{jobs: make(map[int]*schedulerJob), wake: make(chan struct{}, 1)}
+}
+
+// Add — Register a job on a cron expression, see ParseCron, and return its id
+// Jobs can be added before or while the scheduler runs.
+func (s *Scheduler) Add(spec string, policy OverlapPolicy, job func(ctx context.Context)) (int, error) {
+	c, err := ParseCron(spec)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	s.nextID++
+	id := s.nextID
+	s.jobs[id] = &schedulerJob{id: id, cron: c, policy: policy, fn: job}
+	s.mu.Unlock()
+
+	s.notify()
+	return id, nil
+}
+
+// Remove — Unregister a job, runs already started finish normally
+func (s *Scheduler) Remove(id int) {
+	s.mu.Lock()
+	delete(s.jobs, id)
+	s.mu.Unlock()
+
+	s.notify()
+}
+
+// Next — Return the next time a job is due, ok is false for unknown ids or before Run
+func (s *Scheduler) Next(id int) (next time.Time, ok bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	job, ok := s.jobs[id]
+	if !ok || job.next.IsZero() {
+		return next, false
+	}
+	return job.next, true
+}
+
+// notify wakes Run up to take job changes into account
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run — Run the jobs until ctx is cancelled, then wait for the running ones and return ctx.Err()
+func (s *Scheduler) Run(ctx context.Context) error {
+	for {
+		clock := getClock()
+		now := clock.Now()
+		next := s.dispatch(ctx, now)
+
+		wait := time.Hour
+		if !next.IsZero() {
+			wait = next.Sub(now)
+		}
+
+		timer, stop := clock.Timer(wait)
+		select {
+		case <-ctx.Done():
+			stop()
+			s.wg.Wait()
+			return ctx.Err()
+		case <-s.wake:
+		case <-timer:
+		}
+		stop()
+	}
+}
+
+// dispatch starts the jobs due at now and returns when the next one is due, zero when none is
+func (s *Scheduler) dispatch(ctx context.Context, now time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ids := make([]int, 0, len(s.jobs))
+	for id := range s.jobs {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var next time.Time
+	for _, id := range ids {
+		job := s.jobs[id]
+		if job.next.IsZero() {
+			job.next = job.cron.Next(now)
+		} else if !job.next.After(now) {
+			s.start(ctx, job)
+			job.next = job.cron.Next(now)
+		}
+
+		if !job.next.IsZero() && (next.IsZero() || job.next.Before(next)) {
+			next = job.next
+		}
+	}
+	return next
+}
+
+// start runs job according to its overlap policy, s.mu must be held
+func (s *Scheduler) start(ctx context.Context, job *schedulerJob) {
+	if job.running > 0 {
+		switch job.policy {
+		case OverlapSkip:
+			return
+		case OverlapQueue:
+			job.queued++
+			return
+		}
+	}
+
+	job.running++
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		for {
+			s.call(ctx, job)
+
+			s.mu.Lock()
+			if job.queued == 0 || ctx.Err() != nil {
+				job.running--
+				job.queued = 0
+				s.mu.Unlock()
+				return
+			}
+			job.queued--
+			s.mu.Unlock()
+		}
+	}()
+}
+
+// call runs job once, recovering a panic
+func (s *Scheduler) call(ctx context.Context, job *schedulerJob) {
+	defer func() {
+		if r := recover(); r != nil {
+			if s.OnPanic != nil {
+				s.OnPanic(job.id, r)
+				return
+			}
+			log.Printf("utils: scheduler job %d (%s) panicked: %v\n%s", job.id, job.cron, r, debug.Stack())
+		}
+	}()
+
+	job.fn(ctx)
+}
@@ -0,0 +1,182 @@
+package utils
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCron(t *testing.T) {
+	layout := "2006-01-02 15:04:05 Mon -0700"
+	from := time.Date(2018, 4, 27, 10, 23, 14, 500, time.UTC) // Friday
+
+	nexts := []struct {
+		spec, expected string
+	}{
+		{"* * * * *", "2018-04-27 10:24:00 Fri +0000"},
+		{"* * * * * *", "2018-04-27 10:23:15 Fri +0000"},
+		{"*/15 * * * * *", "2018-04-27 10:23:15 Fri +0000"},
+		{"30 2 * * 1-5", "2018-04-30 02:30:00 Mon +0000"},
+		{"0 0 * * 0", "2018-04-29 00:00:00 Sun +0000"},
+		{"0 0 * * 7", "2018-04-29 00:00:00 Sun +0000"},
+		{"0 0 * * SUN", "2018-04-29 00:00:00 Sun +0000"},
+		{"0 0 * * 1/2", "2018-04-30 00:00:00 Mon +0000"},
+		{"0 9-17/4 * * *", "2018-04-27 13:00:00 Fri +0000"},
+		{"0 8,12 * * *", "2018-04-27 12:00:00 Fri +0000"},
+		{"0 0 1 jan *", "2019-01-01 00:00:00 Tue +0000"},
+		{"0 0 29 2 *", "2020-02-29 00:00:00 Sat +0000"},
+		{"0 0 L * ?", "2018-04-30 00:00:00 Mon +0000"},
+		{"0 0 L-2 * *", "2018-04-28 00:00:00 Sat +0000"},
+		{"0 0 LW 6 *", "2018-06-29 00:00:00 Fri +0000"},
+		{"0 0 15W 9 *", "2018-09-14 00:00:00 Fri +0000"},
+		{"0 0 1W 9 *", "2018-09-03 00:00:00 Mon +0000"},
+		{"0 0 30W 9 *", "2018-09-28 00:00:00 Fri +0000"},
+		{"0 0 ? * 5L", "2018-05-25 00:00:00 Fri +0000"},
+		{"0 0 ? * FRI#3", "2018-05-18 00:00:00 Fri +0000"},
+		{"0 0 ? * 1#5", "2018-04-30 00:00:00 Mon +0000"},
+		{"0 0 13 * 5", "2018-05-04 00:00:00 Fri +0000"},
+		{"0 0 */10 * 5", "2018-05-11 00:00:00 Fri +0000"},
+		{"@hourly", "2018-04-27 11:00:00 Fri +0000"},
+		{"@daily", "2018-04-28 00:00:00 Sat +0000"},
+		{"@weekly", "2018-04-29 00:00:00 Sun +0000"},
+		{"@monthly", "2018-05-01 00:00:00 Tue +0000"},
+		{"@yearly", "2019-01-01 00:00:00 Tue +0000"},
+		{"CRON_TZ=Asia/Jakarta 0 0 * * *", "2018-04-27 17:00:00 Fri +0000"},
+		{"TZ=America/New_York 0 9 * * *", "2018-04-27 13:00:00 Fri +0000"},
+	}
+	for _, c := range nexts {
+		cron, err := ParseCron(c.spec)
+		equal(t, nil, err)
+		equal(t, c.spec, cron.String())
+		equal(t, c.expected, cron.Next(from).Format(layout))
+	}
+
+	// Next is strictly after and keeps the location of the time given
+	cron, _ := ParseCron("23 10 * * *")
+	equal(t, "2018-04-28 10:23:00 Sat +0000", cron.Next(from).Format(layout))
+	jakarta := time.FixedZone("WIB", 7*3600)
+	equal(t, "2018-04-28 10:23:00 Sat +0700", cron.Next(from.In(jakarta)).Format(layout))
+	cron, _ = ParseCron("CRON_TZ=UTC 23 10 * * *")
+	equal(t, "2018-04-28 17:23:00 Sat +0700", cron.Next(from.In(jakarta)).Format(layout))
+
+	cron, _ = ParseCron("0 0 30 2 *")
+	equal(t, true, cron.Next(from).IsZero())
+
+	// wall times skipped by DST don't run, repeated ones run once
+	amsterdam, _ := time.LoadLocation("Europe/Amsterdam")
+	cron, _ = ParseCron("30 2 * * *")
+	spring := time.Date(2021, 3, 27, 12, 0, 0, 0, amsterdam)
+	equal(t, "2021-03-29 02:30:00 Mon +0200", cron.Next(spring).Format(layout))
+	autumn := cron.Next(time.Date(2021, 10, 30, 12, 0, 0, 0, amsterdam))
+	equal(t, "2021-10-31", autumn.Format("2006-01-02"))
+	equal(t, "2021-11-01 02:30:00 Mon +0100", cron.Next(autumn).Format(layout))
+	cron, _ = ParseCron("0 * * * *")
+	equal(t, "2021-10-31 03:00:00 Sun +0100", cron.Next(time.Date(2021, 10, 31, 0, 30, 0, 0, time.UTC).In(amsterdam)).Format(layout))
+
+	invalid := []string{
+		"", "* * * *", "* * * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "* * 32 * *",
+		"* * * 13 *", "* * * * 8", "* * * foo *", "5-1 * * * *", "*/0 * * * *", "* * L-31 * *",
+		"* * 32W * *", "* * * * 5#6", "* * * * 9L", "? * * * *", "@every", "CRON_TZ=Mars/Olympus * * * * *",
+	}
+	for _, spec := range invalid {
+		_, err := ParseCron(spec)
+		unequal(t, nil, err)
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	s := NewScheduler()
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	runs := map[string]int{}
+	release := make(chan struct{})
+	job := func(name string) func(context.Context) {
+		return func(context.Context) {
+			mu.Lock()
+			runs[name]++
+			mu.Unlock()
+			<-release
+		}
+	}
+
+	skip, err := s.Add("* * * * *", OverlapSkip, job("skip"))
+	equal(t, nil, err)
+	_, err = s.Add("* * * * *", OverlapQueue, job("queue"))
+	equal(t, nil, err)
+	_, err = s.Add("* * * * *", OverlapConcurrent, job("concurrent"))
+	equal(t, nil, err)
+	_, err = s.Add("* * * *", OverlapSkip, job("invalid"))
+	unequal(t, nil, err)
+
+	_, ok := s.Next(skip)
+	equal(t, false, ok)
+
+	minute := time.Date(2018, 4, 27, 10, 23, 0, 0, time.UTC)
+	equal(t, minute.Add(time.Minute), s.dispatch(ctx, minute))
+	next, ok := s.Next(skip)
+	equal(t, true, ok)
+	equal(t, minute.Add(time.Minute), next)
+
+	// three due runs while the first ones block
+	for i := 1; i <= 3; i++ {
+		s.dispatch(ctx, minute.Add(time.Duration(i)*time.Minute))
+	}
+	waitFor := func(expected map[string]int) {
+		for i := 0; i < 200; i++ {
+			mu.Lock()
+			done := len(runs) == len(expected)
+			for k, v := range expected {
+				done = done && runs[k] == v
+			}
+			mu.Unlock()
+			if done {
+				break
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		mu.Lock()
+		equal(t, expected, runs)
+		mu.Unlock()
+	}
+	waitFor(map[string]int{"skip": 1, "queue": 1, "concurrent": 3})
+
+	// the queued runs follow one by one
+	close(release)
+	waitFor(map[string]int{"skip": 1, "queue": 3, "concurrent": 3})
+	s.wg.Wait()
+
+	s.Remove(skip)
+	_, ok = s.Next(skip)
+	equal(t, false, ok)
+
+	var recovered interface{}
+	p := NewScheduler()
+	p.OnPanic = func(id int, r interface{}) { recovered = r }
+	p.Add("* * * * *", OverlapSkip, func(context.Context) { panic("boom") })
+	p.dispatch(ctx, minute)
+	p.dispatch(ctx, minute.Add(time.Minute))
+	p.wg.Wait()
+	equal(t, "boom", recovered)
+
+	// Run starts jobs on the package clock and waits for them once cancelled
+	fake := NewFakeClock(minute.Add(30 * time.Second))
+	defer SetClock(SetClock(fake))
+	r := NewScheduler()
+	started := make(chan time.Time, 10)
+	r.Add("* * * * *", OverlapSkip, func(ctx context.Context) {
+		started <- fake.Now()
+		<-ctx.Done()
+	})
+	ctx, cancel := context.WithCancel(ctx)
+	errs := make(chan error)
+	go func() { errs <- r.Run(ctx) }()
+	fake.BlockUntil(1)
+	fake.Advance(30 * time.Second)
+	equal(t, minute.Add(time.Minute), <-started)
+	r.Add("0 * * * *", OverlapSkip, func(context.Context) {})
+	cancel()
+	equal(t, context.Canceled, <-errs)
+	equal(t, 0, fake.Waiters())
+}
@@ -1,19 +1,86 @@
 package utils
 
 import (
+	"context"
+	"fmt"
+	"sync"
 	"time"
 )
 
+var (
+	locationMu sync.RWMutex
+	location   = time.Local
+
+	weekStartMu  sync.RWMutex
+	weekStartDay = time.Sunday
+)
+
+// SetLocation — Replace the package default timezone and return the previous one
+// Date, PhpDate, Mktime, StrToTimeFrom and DateCreateFromFormat use the default timezone.
+// Passing nil restores the local timezone of the process.
+// Usage in tests:
+// defer SetLocation(SetLocation(time.UTC))
+func SetLocation(loc *time.Location) *time.Location {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	locationMu.Lock()
+	defer locationMu.Unlock()
+
+	prev := location
+	location = loc
+	return prev
+}
+
+// getLocation returns the package default timezone
+func getLocation() *time.Location {
+	locationMu.RLock()
+	defer locationMu.RUnlock()
+	return location
+}
+
+// DateDefaultTimezoneSet — Sets the default timezone used by all date/time functions
+// DateDefaultTimezoneSet("Europe/Amsterdam")
+func DateDefaultTimezoneSet(timezoneID string) error {
+	loc, err := time.LoadLocation(timezoneID)
+	if err != nil {
+		return err
+	}
+
+	SetLocation(loc)
+	return nil
+}
+
+// DateDefaultTimezoneGet — Gets the default timezone used by all date/time functions
+func DateDefaultTimezoneGet() string {
+	return timezoneIdentifier(getClock().Now().In(getLocation()))
+}
+
 // Time — Return current Unix timestamp
 func Time() int64 {
-	return time.Now().Unix()
+	return getClock().Now().Unix()
+}
+
+// TimeIn — Return the current time in loc
+func TimeIn(loc *time.Location) time.Time {
+	return getClock().Now().In(loc)
 }
 
-// StrToTime — Parse about any English textual datetime description into a Unix timestamp
+// StrToTime — Parse a datetime string laid out like format into a Unix timestamp
+// For English textual descriptions such as "next monday" use StrToTimeFrom or ParseStrToTime.
 // StrToTime("02/01/2006 15:04:05", "02/01/2016 15:04:05") == 1451747045
 // StrToTime("3 04 PM", "8 41 PM") == -62167144740
+// Without a timezone in strtime, it is parsed as UTC; use StrToTimeIn to choose the timezone.
 func StrToTime(format, strtime string) (int64, error) {
-	t, err := time.Parse(format, strtime)
+	return StrToTimeIn(format, strtime, time.UTC)
+}
+
+// StrToTimeIn — Parse a datetime string laid out like format in loc into a Unix timestamp
+// A timezone in strtime takes precedence over loc.
+// StrToTimeIn("2006-01-02 15:04", "2018-04-27 10:23", jakarta) == 1524799380
+func StrToTimeIn(format, strtime string, loc *time.Location) (int64, error) {
+	t, err := time.ParseInLocation(format, strtime, loc)
 	if err != nil {
 		return 0, err
 	}
@@ -22,9 +89,47 @@ func StrToTime(format, strtime string) (int64, error) {
 }
 
 // Date — Format a local time/date
+// The time is formatted in the package default timezone, see SetLocation.
 // Date("02/01/2006 15:04:05 PM", 1524799394)
 func Date(format string, timestamp int64) string {
-	return time.Unix(timestamp, 0).Format(format)
+	return DateIn(format, timestamp, getLocation())
+}
+
+// DateIn — Format a time/date in loc
+func DateIn(format string, timestamp int64, loc *time.Location) string {
+	return time.Unix(timestamp, 0).In(loc).Format(format)
+}
+
+// GmDate — Format a GMT/UTC date/time
+// GmDate("2006-01-02 15:04:05", 1524799394) == "2018-04-27 03:23:14"
+func GmDate(format string, timestamp int64) string {
+	return DateIn(format, timestamp, time.UTC)
+}
+
+// Mktime — Get Unix timestamp for a date in the package default timezone
+// Out of range values are normalized: month 13 is January of the next year, day 0 the last day of
+// the previous month, hour -1 the last hour of the previous day. Years 0-69 are 2000-2069 and
+// years 70-100 are 1970-2000, as in PHP.
+// Mktime(0, 0, 0, 13, 1, 2017) == Mktime(0, 0, 0, 1, 1, 2018)
+func Mktime(hour, minute, second, month, day, year int) int64 {
+	return MktimeIn(hour, minute, second, month, day, year, getLocation())
+}
+
+// GmMktime — Get Unix timestamp for a GMT date
+func GmMktime(hour, minute, second, month, day, year int) int64 {
+	return MktimeIn(hour, minute, second, month, day, year, time.UTC)
+}
+
+// MktimeIn — Get Unix timestamp for a date in loc
+func MktimeIn(hour, minute, second, month, day, year int, loc *time.Location) int64 {
+	switch {
+	case year >= 0 && year < 70:
+		year += 2000
+	case year >= 70 && year <= 100:
+		year += 1900
+	}
+
+	return time.Date(year, time.Month(month), day, hour, minute, second, 0, loc).Unix()
 }
 
 // CheckDate — Validate a Gregorian date
@@ -54,10 +159,297 @@ func CheckDate(month, day, year int) bool {
 
 // Sleep — Delay execution
 func Sleep(t int64) {
-	time.Sleep(time.Duration(t) * time.Second)
+	<-getClock().After(time.Duration(t) * time.Second)
 }
 
 // Usleep — Delay execution in microseconds
 func Usleep(t int64) {
-	time.Sleep(time.Duration(t) * time.Microsecond)
+	<-getClock().After(time.Duration(t) * time.Microsecond)
+}
+
+// SleepContext — Delay execution for t seconds, returning ctx.Err() early if ctx is done
+func SleepContext(ctx context.Context, t int64) error {
+	return sleepContext(ctx, time.Duration(t)*time.Second)
+}
+
+// UsleepContext — Delay execution for t microseconds, returning ctx.Err() early if ctx is done
+func UsleepContext(ctx context.Context, t int64) error {
+	return sleepContext(ctx, time.Duration(t)*time.Microsecond)
+}
+
+// sleepContext waits for d on the package clock or for ctx to be done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	timer, stop := getClock().Timer(d)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer:
+		return nil
+	}
+}
+
+// TimeSleepUntil — Make the script sleep until the specified time
+// timestamp is a Unix timestamp with fractions of a second. It returns false without sleeping when
+// the time has passed.
+// TimeSleepUntil(Microtime(true).(float64) + 1.5)
+func TimeSleepUntil(timestamp float64) bool {
+	c := getClock()
+	d := time.Unix(0, int64(timestamp*1e9)).Sub(c.Now())
+	if d <= 0 {
+		return false
+	}
+
+	<-c.After(d)
+	return true
+}
+
+// Microtime — Return current Unix timestamp with microseconds
+// Microtime(false) returns a string "msec sec" like "0.65432100 1524799394",
+// Microtime(true) a float64 of seconds.
+func Microtime(asFloat bool) interface{} {
+	now := getClock().Now()
+	usec := now.Nanosecond() / 1000
+
+	if asFloat {
+		return float64(now.Unix()) + float64(usec)/1e6
+	}
+	return fmt.Sprintf("%.8f %d", float64(usec)/1e6, now.Unix())
+}
+
+// Hrtime — Get the system's high resolution time
+// The time counts nanoseconds from an arbitrary point, the start of the program or the installation
+// of the clock, and is only useful to measure durations. Hrtime(true) returns an int64 of nanoseconds,
+// Hrtime(false) a []int64 of seconds and nanoseconds.
+func Hrtime(asNumber bool) interface{} {
+	clockMu.RLock()
+	c, start := clock, clockStart
+	clockMu.RUnlock()
+
+	ns := int64(c.Now().Sub(start))
+
+	if asNumber {
+		return ns
+	}
+	return []int64{ns / 1e9, ns % 1e9}
+}
+
+// SetWeekStartDay — Replace the package default first day of the week and return the previous one
+// NewNow and Parse use the default, it is Sunday unless changed.
+// defer SetWeekStartDay(SetWeekStartDay(time.Monday))
+func SetWeekStartDay(day time.Weekday) time.Weekday {
+	weekStartMu.Lock()
+	defer weekStartMu.Unlock()
+
+	prev := weekStartDay
+	weekStartDay = day
+	return prev
+}
+
+// getWeekStartDay returns the package default first day of the week
+func getWeekStartDay() time.Weekday {
+	weekStartMu.RLock()
+	defer weekStartMu.RUnlock()
+	return weekStartDay
+}
+
+// Now — A time with helpers for the beginning and end of the period it falls in
+// Boundaries are computed on the wall clock of the time's location, so a day is not always 24 hours.
+// NewNow(t).BeginningOfMonth()
+// NewNow(t).EndOfQuarter()
+type Now struct {
+	time.Time
+	WeekStartDay time.Weekday
+}
+
+// NewNow — Wrap t with the package default first day of the week
+func NewNow(t time.Time) *Now {
+	return &Now{Time: t, WeekStartDay: getWeekStartDay()}
+}
+
+// startOfDay returns the first instant of a day in loc
+// A few zones skip midnight when DST starts, their day then begins at the transition.
+func startOfDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+
+	// time.Date normalizes out of range days, compare against the normalized date
+	_, _, want := time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Date()
+	if t.Day() != want {
+		_, end := t.ZoneBounds()
+		return end
+	}
+
+	return t
+}
+
+// BeginningOfMinute — Return the first instant of the minute
+func (now *Now) BeginningOfMinute() time.Time {
+	return now.Add(-time.Duration(now.Second())*time.Second - time.Duration(now.Nanosecond()))
+}
+
+// BeginningOfHour — Return the first instant of the hour
+func (now *Now) BeginningOfHour() time.Time {
+	return now.BeginningOfMinute().Add(-time.Duration(now.Minute()) * time.Minute)
+}
+
+// BeginningOfDay — Return the first instant of the day
+func (now *Now) BeginningOfDay() time.Time {
+	y, m, d := now.Date()
+	return startOfDay(y, m, d, now.Location())
+}
+
+// BeginningOfWeek — Return the first instant of the week, which starts on WeekStartDay
+func (now *Now) BeginningOfWeek() time.Time {
+	y, m, d := now.Date()
+	offset := (int(now.Weekday()) - int(now.WeekStartDay) + 7) % 7
+	return startOfDay(y, m, d-offset, now.Location())
+}
+
+// BeginningOfMonth — Return the first instant of the month
+func (now *Now) BeginningOfMonth() time.Time {
+	y, m, _ := now.Date()
+	return startOfDay(y, m, 1, now.Location())
+}
+
+// BeginningOfQuarter — Return the first instant of the quarter
+func (now *Now) BeginningOfQuarter() time.Time {
+	y, m, _ := now.Date()
+	return startOfDay(y, m-(m-1)%3, 1, now.Location())
+}
+
+// BeginningOfYear — Return the first instant of the year
+func (now *Now) BeginningOfYear() time.Time {
+	return startOfDay(now.Year(), time.January, 1, now.Location())
+}
+
+// EndOfMinute — Return the last nanosecond of the minute
+func (now *Now) EndOfMinute() time.Time {
+	return now.BeginningOfMinute().Add(time.Minute - time.Nanosecond)
+}
+
+// EndOfHour — Return the last nanosecond of the hour
+func (now *Now) EndOfHour() time.Time {
+	return now.BeginningOfHour().Add(time.Hour - time.Nanosecond)
+}
+
+// EndOfDay — Return the last nanosecond of the day
+func (now *Now) EndOfDay() time.Time {
+	y, m, d := now.Date()
+	return startOfDay(y, m, d+1, now.Location()).Add(-time.Nanosecond)
+}
+
+// EndOfWeek — Return the last nanosecond of the week
+func (now *Now) EndOfWeek() time.Time {
+	y, m, d := now.BeginningOfWeek().Date()
+	return startOfDay(y, m, d+7, now.Location()).Add(-time.Nanosecond)
+}
+
+// EndOfMonth — Return the last nanosecond of the month
+func (now *Now) EndOfMonth() time.Time {
+	y, m, _ := now.Date()
+	return startOfDay(y, m+1, 1, now.Location()).Add(-time.Nanosecond)
+}
+
+// EndOfQuarter — Return the last nanosecond of the quarter
+func (now *Now) EndOfQuarter() time.Time {
+	y, m, _ := now.Date()
+	return startOfDay(y, m-(m-1)%3+3, 1, now.Location()).Add(-time.Nanosecond)
+}
+
+// EndOfYear — Return the last nanosecond of the year
+func (now *Now) EndOfYear() time.Time {
+	return startOfDay(now.Year()+1, time.January, 1, now.Location()).Add(-time.Nanosecond)
+}
+
+// Monday — Return the first instant of the Monday of the ISO week, Monday to Sunday
+func (now *Now) Monday() time.Time {
+	y, m, d := now.Date()
+	return startOfDay(y, m, d-isoWeekday(now.Time)+1, now.Location())
+}
+
+// Sunday — Return the first instant of the Sunday of the ISO week, Monday to Sunday
+func (now *Now) Sunday() time.Time {
+	y, m, d := now.Date()
+	return startOfDay(y, m, d-isoWeekday(now.Time)+7, now.Location())
+}
+
+// EndOfSunday — Return the last nanosecond of the Sunday of the ISO week
+func (now *Now) EndOfSunday() time.Time {
+	y, m, d := now.Date()
+	return startOfDay(y, m, d-isoWeekday(now.Time)+8, now.Location()).Add(-time.Nanosecond)
+}
+
+// Next — Return the first instant of the next day falling on weekday, never today
+// NewNow(friday).Next(time.Friday) is a week later
+func (now *Now) Next(weekday time.Weekday) time.Time {
+	y, m, d := now.Date()
+	days := (int(weekday)-int(now.Weekday())+6)%7 + 1
+	return startOfDay(y, m, d+days, now.Location())
+}
+
+// Previous — Return the first instant of the previous day falling on weekday, never today
+func (now *Now) Previous(weekday time.Weekday) time.Time {
+	y, m, d := now.Date()
+	days := (int(now.Weekday())-int(weekday)+6)%7 + 1
+	return startOfDay(y, m, d-days, now.Location())
+}
+
+// parseLayouts are tried in order by Parse, with the components each one carries
+var parseLayouts = []struct {
+	layout     string
+	year, date bool
+}{
+	{time.RFC3339Nano, true, true},
+	{"2006-01-02T15:04:05", true, true},
+	{"2006-1-2 15:4:5 -0700", true, true},
+	{"2006-1-2 15:4:5", true, true},
+	{"2006-1-2 15:4", true, true},
+	{"2006-1-2", true, true},
+	{"2006-1", true, true},
+	{"2006", true, true},
+	{"1-2 15:4:5", false, true},
+	{"1-2 15:4", false, true},
+	{"1-2", false, true},
+	{"15:4:5", false, false},
+	{"15:4", false, false},
+	{time.RFC1123Z, true, true},
+	{time.RFC1123, true, true},
+}
+
+// Parse — Parse str relative to the wrapped time
+// Components missing from str above the ones given come from the wrapped time, the ones below are zero:
+// a time alone is on the wrapped day, a month and day are in the wrapped year, a year is its January 1st.
+// Without an offset in str, it is parsed in the location of the wrapped time.
+// NewNow(t).Parse("10:30")      // today at 10:30
+// NewNow(t).Parse("12-25")      // December 25th this year
+// NewNow(t).Parse("2018-04")    // 2018-04-01 00:00:00
+func (now *Now) Parse(str string) (time.Time, error) {
+	for _, l := range parseLayouts {
+		t, err := time.ParseInLocation(l.layout, str, now.Location())
+		if err != nil {
+			continue
+		}
+
+		y, m, d := t.Date()
+		if !l.date {
+			y, m, d = now.Date()
+		} else if !l.year {
+			y = now.Year()
+		}
+		return time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location()), nil
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse %q as a time", str)
+}
+
+// Parse — Parse str relative to the current time in the package default timezone, see Now.Parse
+// Parse("10:30")
+// Parse("2018-04-27 10:23")
+func Parse(str string) (time.Time, error) {
+	return NewNow(getClock().Now().In(getLocation())).Parse(str)
 }
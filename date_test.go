@@ -2,6 +2,7 @@ package utils
 
 import (
 	"testing"
+	"time"
 )
 
 func TestDate(t *testing.T) {
@@ -19,4 +20,144 @@ func TestDate(t *testing.T) {
 
 	equal(t, false, CheckDate(2, 29, 2018))
 	equal(t, true, CheckDate(2, 29, 2020))
+
+	amsterdam, _ := time.LoadLocation("Europe/Amsterdam")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	equal(t, "2018-04-27 03:23:14", GmDate("2006-01-02 15:04:05", 1524799394))
+	equal(t, "2018-04-27 05:23:14 CEST", DateIn("2006-01-02 15:04:05 MST", 1524799394, amsterdam))
+	equal(t, "2018-04-26 23:23:14 EDT", DateIn("2006-01-02 15:04:05 MST", 1524799394, newYork))
+
+	tstrtotime3, _ := StrToTimeIn("2006-01-02 15:04:05", "2018-04-27 05:23:14", amsterdam)
+	equal(t, int64(1524799394), tstrtotime3)
+	tstrtotime4, _ := StrToTimeIn("2006-01-02 15:04:05 -0700", "2018-04-27 03:23:14 +0000", amsterdam)
+	equal(t, int64(1524799394), tstrtotime4)
+	equal(t, newYork, TimeIn(newYork).Location())
+
+	defer SetLocation(SetLocation(amsterdam))
+	equal(t, "2018-04-27 05:23:14", Date("2006-01-02 15:04:05", 1524799394))
+	equal(t, "2018-04-27 05:23:14", PhpDate("Y-m-d H:i:s", 1524799394))
+	equal(t, "Europe/Amsterdam", DateDefaultTimezoneGet())
+
+	equal(t, nil, DateDefaultTimezoneSet("America/New_York"))
+	equal(t, "America/New_York", DateDefaultTimezoneGet())
+	unequal(t, nil, DateDefaultTimezoneSet("Mars/Olympus"))
+	equal(t, "America/New_York", DateDefaultTimezoneGet())
+
+	equal(t, int64(1524799394), Mktime(23, 23, 14, 4, 26, 2018))
+	equal(t, int64(1524799394), GmMktime(3, 23, 14, 4, 27, 2018))
+	equal(t, int64(1524799394), MktimeIn(5, 23, 14, 4, 27, 2018, amsterdam))
+
+	// overflow normalisation
+	equal(t, GmMktime(0, 0, 0, 1, 1, 2018), GmMktime(0, 0, 0, 13, 1, 2017))
+	equal(t, GmMktime(0, 0, 0, 2, 29, 2020), GmMktime(0, 0, 0, 3, 0, 2020))
+	equal(t, GmMktime(0, 0, 0, 12, 31, 2017), GmMktime(0, 0, 0, 1, 0, 2018))
+	equal(t, GmMktime(23, 0, 0, 12, 31, 2017), GmMktime(-1, 0, 0, 1, 1, 2018))
+	equal(t, GmMktime(0, 1, 40, 1, 1, 2018), GmMktime(0, 0, 100, 1, 1, 2018))
+	equal(t, GmMktime(0, 0, 0, 11, 1, 2017), GmMktime(0, 0, 0, -1, 1, 2018))
+	equal(t, GmMktime(0, 0, 0, 1, 1, 2018), GmMktime(0, 0, 0, 1, 1, 18))
+	equal(t, GmMktime(0, 0, 0, 1, 1, 1999), GmMktime(0, 0, 0, 1, 1, 99))
+	equal(t, GmMktime(0, 0, 0, 1, 1, 2000), GmMktime(0, 0, 0, 1, 1, 100))
+	equal(t, int64(0), GmMktime(0, 0, 0, 1, 1, 1970))
+
+	SetLocation(nil)
+	equal(t, time.Local, getLocation())
+}
+
+func TestNow(t *testing.T) {
+	layout := "2006-01-02 15:04:05.999999999 -0700"
+	tm := time.Date(2018, 4, 27, 10, 23, 14, 123456789, time.UTC) // Friday
+	n := NewNow(tm)
+	equal(t, time.Sunday, n.WeekStartDay)
+
+	boundaries := []struct {
+		got      time.Time
+		expected string
+	}{
+		{n.BeginningOfMinute(), "2018-04-27 10:23:00 +0000"},
+		{n.BeginningOfHour(), "2018-04-27 10:00:00 +0000"},
+		{n.BeginningOfDay(), "2018-04-27 00:00:00 +0000"},
+		{n.BeginningOfWeek(), "2018-04-22 00:00:00 +0000"},
+		{n.BeginningOfMonth(), "2018-04-01 00:00:00 +0000"},
+		{n.BeginningOfQuarter(), "2018-04-01 00:00:00 +0000"},
+		{n.BeginningOfYear(), "2018-01-01 00:00:00 +0000"},
+		{n.EndOfMinute(), "2018-04-27 10:23:59.999999999 +0000"},
+		{n.EndOfHour(), "2018-04-27 10:59:59.999999999 +0000"},
+		{n.EndOfDay(), "2018-04-27 23:59:59.999999999 +0000"},
+		{n.EndOfWeek(), "2018-04-28 23:59:59.999999999 +0000"},
+		{n.EndOfMonth(), "2018-04-30 23:59:59.999999999 +0000"},
+		{n.EndOfQuarter(), "2018-06-30 23:59:59.999999999 +0000"},
+		{n.EndOfYear(), "2018-12-31 23:59:59.999999999 +0000"},
+		{n.Monday(), "2018-04-23 00:00:00 +0000"},
+		{n.Sunday(), "2018-04-29 00:00:00 +0000"},
+		{n.EndOfSunday(), "2018-04-29 23:59:59.999999999 +0000"},
+		{n.Next(time.Friday), "2018-05-04 00:00:00 +0000"},
+		{n.Next(time.Monday), "2018-04-30 00:00:00 +0000"},
+		{n.Previous(time.Friday), "2018-04-20 00:00:00 +0000"},
+		{n.Previous(time.Thursday), "2018-04-26 00:00:00 +0000"},
+	}
+	for _, c := range boundaries {
+		equal(t, c.expected, c.got.Format(layout))
+	}
+
+	n.WeekStartDay = time.Monday
+	equal(t, "2018-04-23 00:00:00 +0000", n.BeginningOfWeek().Format(layout))
+	equal(t, "2018-04-29 23:59:59.999999999 +0000", n.EndOfWeek().Format(layout))
+	n.WeekStartDay = time.Saturday
+	equal(t, "2018-04-21 00:00:00 +0000", n.BeginningOfWeek().Format(layout))
+
+	defer SetWeekStartDay(SetWeekStartDay(time.Monday))
+	equal(t, time.Monday, NewNow(tm).WeekStartDay)
+
+	// Sunday belongs to the ISO week that started on the Monday before
+	sunday := NewNow(time.Date(2018, 4, 29, 12, 0, 0, 0, time.UTC))
+	equal(t, "2018-04-23", sunday.Monday().Format("2006-01-02"))
+	equal(t, "2018-04-29", sunday.Sunday().Format("2006-01-02"))
+	equal(t, "2018-12-31", NewNow(time.Date(2018, 11, 15, 0, 0, 0, 0, time.UTC)).EndOfQuarter().Format("2006-01-02"))
+
+	// days are 23 or 25 hours long around DST changes
+	amsterdam, _ := time.LoadLocation("Europe/Amsterdam")
+	spring := NewNow(time.Date(2021, 3, 28, 12, 0, 0, 0, amsterdam))
+	equal(t, 23*time.Hour, spring.EndOfDay().Sub(spring.BeginningOfDay())+time.Nanosecond)
+	equal(t, "2021-03-28 23:59:59.999999999 +0200", spring.EndOfDay().Format(layout))
+	autumn := NewNow(time.Date(2021, 10, 31, 1, 30, 0, 0, time.UTC).In(amsterdam)) // the second 02:30
+	equal(t, "2021-10-31 02:00:00 +0100", autumn.BeginningOfHour().Format(layout))
+	equal(t, 25*time.Hour, autumn.EndOfDay().Sub(autumn.BeginningOfDay())+time.Nanosecond)
+
+	// Sao Paulo skipped midnight on 2018-11-04, the day started at 01:00
+	saoPaulo, _ := time.LoadLocation("America/Sao_Paulo")
+	skipped := NewNow(time.Date(2018, 11, 4, 12, 0, 0, 0, saoPaulo))
+	equal(t, "2018-11-04 01:00:00 -0200", skipped.BeginningOfDay().Format(layout))
+	equal(t, "2018-11-03 23:59:59.999999999 -0300", NewNow(time.Date(2018, 11, 3, 12, 0, 0, 0, saoPaulo)).EndOfDay().Format(layout))
+	week := &Now{Time: time.Date(2018, 11, 8, 12, 0, 0, 0, saoPaulo), WeekStartDay: time.Sunday}
+	equal(t, "2018-11-04 01:00:00 -0200", week.BeginningOfWeek().Format(layout))
+
+	parsed := map[string]string{
+		"10:30":                     "2018-04-27 10:30:00 +0000",
+		"10:30:15":                  "2018-04-27 10:30:15 +0000",
+		"12-25":                     "2018-12-25 00:00:00 +0000",
+		"12-25 08:00":               "2018-12-25 08:00:00 +0000",
+		"2017":                      "2017-01-01 00:00:00 +0000",
+		"2017-6":                    "2017-06-01 00:00:00 +0000",
+		"2017-06-15":                "2017-06-15 00:00:00 +0000",
+		"2017-06-15 10:20":          "2017-06-15 10:20:00 +0000",
+		"2017-06-15 10:20:30.5":     "2017-06-15 10:20:30.5 +0000",
+		"2017-06-15 10:20:30 +0700": "2017-06-15 10:20:30 +0700",
+		"2017-06-15T10:20:30Z":      "2017-06-15 10:20:30 +0000",
+	}
+	for str, expected := range parsed {
+		p, err := NewNow(tm).Parse(str)
+		equal(t, nil, err)
+		equal(t, expected, p.Format(layout))
+	}
+	_, err := NewNow(tm).Parse("yesterday")
+	unequal(t, nil, err)
+
+	p, _ := NewNow(time.Date(2018, 4, 27, 0, 0, 0, 0, amsterdam)).Parse("10:30")
+	equal(t, "2018-04-27 10:30:00 +0200", p.Format(layout))
+
+	defer SetLocation(SetLocation(amsterdam))
+	p, _ = Parse("10:30")
+	equal(t, amsterdam, p.Location())
+	equal(t, time.Now().In(amsterdam).YearDay(), p.YearDay())
 }
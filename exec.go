@@ -2,126 +2,1123 @@ package utils
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
-	"regexp"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
-// Exec — Execute an external program
-// returnVar, 0: succ; 1: fail
-// Return the last line from the result of the command.
-func Exec(command string, output *[]string, returnVar *int) string {
-	r, _ := regexp.Compile(`[ ]+`)
-	parts := r.Split(command, -1)
+// Result — The outcome of a command started by Run or RunContext
+// ExitCode is the status a POSIX shell would report: the exit status of the program, 128+n when it
+// was killed by signal n, 127 when it could not be found, 126 when it could not be executed and 2
+// when the command line could not be parsed. Err is nil when the exit status is 0, and a
+// *CommandError otherwise.
+type Result struct {
+	Command  string
+	ExitCode int
+	Signal   syscall.Signal // 0 unless the program was killed by a signal
+	Stdout   []byte
+	Stderr   []byte
+	Combined []byte // stdout and stderr interleaved as they were written
+	Omitted  int64  // bytes of Combined left out of the capture, see RunOptions.MaxCapture
+	Duration time.Duration
+	Err      error
+}
 
-	var args []string
-	if len(parts) > 1 {
-		args = parts[1:]
+// Success — Report whether the command ran and exited with status 0
+func (r *Result) Success() bool {
+	return r.Err == nil
+}
+
+// CommandError — The error of a command that could not run or did not exit with status 0
+// Err is the cause when the command could not run, such as an *exec.Error wrapping exec.ErrNotFound.
+type CommandError struct {
+	Command  string
+	ExitCode int
+	Signal   syscall.Signal
+	Err      error
+}
+
+func (e *CommandError) Error() string {
+	switch {
+	case e.Err != nil:
+		return e.Command + ": " + e.Err.Error()
+	case e.Signal != 0:
+		return e.Command + ": killed by signal " + e.Signal.String()
 	}
+	return e.Command + ": exit status " + strconv.Itoa(e.ExitCode)
+}
+
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultGracePeriod — Time left to a canceled command between SIGTERM and SIGKILL
+const DefaultGracePeriod = 5 * time.Second
+
+// RunOptions — Settings of RunContext
+// A command that outlives its context or Timeout is sent SIGTERM, then SIGKILL once GracePeriod has
+// elapsed; a zero GracePeriod means DefaultGracePeriod and a negative one SIGKILL straight away.
+// Each command leads its own process group, which receives the signals, so that the programs it
+// started are stopped with it.
+//
+// The program reads Stdin, or nothing when it is nil. Its output is streamed to Stdout and Stderr
+// and, line by line without the newline, to OnStdoutLine and OnStderrLine, which are never called
+// concurrently. A failing writer is no longer written to and its error is reported in Result.Err;
+// when it is a closed pipe, the program sees a broken pipe as it would in a shell pipeline.
+// Result.Stdout, Stderr and Combined each keep at most MaxCapture bytes, the first HeadCapture of
+// them and the end of the output for the rest; a MaxCapture of 0 keeps everything and a negative
+// one nothing.
+// opts := &RunOptions{Stdout: PrefixWriter(os.Stdout, "[build] "), MaxCapture: 1 << 20, HeadCapture: 4096}
+type RunOptions struct {
+	Timeout      time.Duration
+	GracePeriod  time.Duration
+	Stdin        io.Reader
+	Stdout       io.Writer
+	Stderr       io.Writer
+	OnStdoutLine func(line string)
+	OnStderrLine func(line string)
+	MaxCapture   int
+	HeadCapture  int
+
+	pipedStdout bool // the output feeds the next command of a Pipeline, only Stderr is captured
+}
+
+// Run — Execute an external program and return its outcome
+// The command is split into words like a POSIX shell does, see ShellSplit; environment variables
+// are expanded, but pipes, redirections and other shell operators are not supported.
+// r := Run("grep -c foo file.txt")
+// r.ExitCode == 1 // no match
+func Run(command string) *Result {
+	return RunContext(context.Background(), command, nil)
+}
 
-	cmd := exec.Command(parts[0], args...)
-	out, err := cmd.CombinedOutput()
+// RunContext — Execute an external program until it exits or ctx is done
+// opts may be nil. A canceled command has an Err wrapping the error of the context, so that
+// errors.Is(r.Err, context.DeadlineExceeded) tells a timeout from a failure; its ExitCode is the
+// one of the signal that stopped it, or -1 when ctx was done before it started. The program is run
+// by the package Executor, see SetExecutor.
+// r := RunContext(ctx, "rsync -a src/ dst/", &RunOptions{Timeout: time.Hour, GracePeriod: 10 * time.Second})
+func RunContext(ctx context.Context, command string, opts *RunOptions) *Result {
+	args, err := splitCommand(command)
 	if err != nil {
-		*returnVar = 1
-		return ""
+		return &Result{Command: command, ExitCode: 2, Err: &CommandError{Command: command, ExitCode: 2, Err: err}}
 	}
+	return getExecutor().Run(ctx, Command{Line: command, Args: args}, opts)
+}
+
+// runCommand runs cmd, described by command, as RunContext does without an Executor
+func runCommand(ctx context.Context, command string, cmd *exec.Cmd, opts *RunOptions) *Result {
+	if opts == nil {
+		opts = &RunOptions{}
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	r := &Result{Command: command}
+	fail := func(code int, err error) *Result {
+		r.ExitCode = code
+		r.Err = &CommandError{Command: command, ExitCode: code, Err: err}
+		return r
+	}
+	if err := ctx.Err(); err != nil {
+		return fail(-1, err)
+	}
+
+	out := newRunOutput(opts)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = opts.Stdin, out.stdout, out.stderr
+	setProcessGroup(cmd)
+
+	start := time.Now()
+	if err := cmd.Start(); err != nil {
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+			return fail(127, err)
+		}
+		return fail(126, err)
+	}
+
+	// stop the process group when ctx is done: SIGTERM, then SIGKILL after the grace period
+	var canceled error
+	exited, watched := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(watched)
+		select {
+		case <-exited:
+			return
+		case <-ctx.Done():
+			canceled = ctx.Err()
+		}
+
+		grace := opts.GracePeriod
+		if grace == 0 {
+			grace = DefaultGracePeriod
+		}
+		if grace > 0 {
+			_ = signalProcessGroup(cmd, syscall.SIGTERM)
+			timer, stop := getClock().Timer(grace)
+			defer stop()
+			select {
+			case <-exited:
+				return
+			case <-timer:
+			}
+		}
+		_ = signalProcessGroup(cmd, syscall.SIGKILL)
+	}()
 
-	*returnVar = 0
-	*output = strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	err := cmd.Wait()
+	close(exited)
+	<-watched
 
-	if l := len(*output); l > 0 {
-		return (*output)[l-1]
+	// Wait returns once the output is copied, nothing writes to out any more
+	r.Duration = time.Since(start)
+	out.flush()
+	r.Stdout, r.Stderr, r.Combined, r.Omitted = out.outBuf.Bytes(), out.errBuf.Bytes(), out.combined.Bytes(), out.combined.omitted
+	if err == nil {
+		err = out.err()
 	}
 
-	return ""
+	r.ExitCode = cmd.ProcessState.ExitCode()
+	if status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		r.Signal = status.Signal()
+		r.ExitCode = 128 + int(r.Signal)
+	}
+
+	switch {
+	case canceled != nil:
+		r.Err = &CommandError{Command: command, ExitCode: r.ExitCode, Signal: r.Signal, Err: canceled}
+	case r.ExitCode != 0:
+		r.Err = &CommandError{Command: command, ExitCode: r.ExitCode, Signal: r.Signal}
+	case err != nil:
+		// the output could not be copied or written
+		r.Err = &CommandError{Command: command, Err: err}
+	}
+	return r
 }
 
-// System — Execute an external program and display the output
-// returnVar, 0: succ; 1: fail
-// Returns the last line of the command output on success, and "" on failure.
-func System(command string, returnVar *int) string {
-	*returnVar = 0
+// maxLineLength bounds the line buffered for OnStdoutLine and OnStderrLine, a longer one is cut
+const maxLineLength = 64 * 1024
 
-	var stdBuf bytes.Buffer
-	var err, err1, err2, err3 error
+// runOutput dispatches the output of a command to the capture buffers and to the writers and line
+// callbacks of RunOptions
+type runOutput struct {
+	stdout, stderr   io.Writer
+	outBuf, errBuf   *boundedBuffer
+	combined         *boundedBuffer
+	writers          []*errorWriter
+	outLine, errLine *lineWriter
+}
 
-	r, _ := regexp.Compile(`[ ]+`)
-	parts := r.Split(command, -1)
+func newRunOutput(opts *RunOptions) *runOutput {
+	out := &runOutput{
+		outBuf:   newBoundedBuffer(opts.MaxCapture, opts.HeadCapture),
+		errBuf:   newBoundedBuffer(opts.MaxCapture, opts.HeadCapture),
+		combined: newBoundedBuffer(opts.MaxCapture, opts.HeadCapture),
+	}
 
-	var args []string
-	if len(parts) > 1 {
-		args = parts[1:]
+	// stdout and stderr are copied by two goroutines
+	var combinedMu, lineMu sync.Mutex
+	combined := writerFunc(func(p []byte) (int, error) {
+		combinedMu.Lock()
+		defer combinedMu.Unlock()
+		return out.combined.Write(p)
+	})
+
+	stream := func(buf *boundedBuffer, w io.Writer, onLine func(string), line **lineWriter, capture bool) io.Writer {
+		var writers []io.Writer
+		if capture {
+			writers = append(writers, buf, combined)
+		}
+		if w != nil {
+			ew := &errorWriter{w: w}
+			out.writers = append(out.writers, ew)
+			writers = append(writers, ew)
+		}
+		if onLine != nil {
+			*line = &lineWriter{mu: &lineMu, fn: onLine}
+			writers = append(writers, *line)
+		}
+		return io.MultiWriter(writers...)
 	}
+	out.stdout = stream(out.outBuf, opts.Stdout, opts.OnStdoutLine, &out.outLine, !opts.pipedStdout)
+	out.stderr = stream(out.errBuf, opts.Stderr, opts.OnStderrLine, &out.errLine, true)
+	return out
+}
 
-	cmd := exec.Command(parts[0], args...)
-	stdoutIn, _ := cmd.StdoutPipe()
-	stderrIn, _ := cmd.StderrPipe()
-	stdout := io.MultiWriter(os.Stdout, &stdBuf)
-	stderr := io.MultiWriter(os.Stderr, &stdBuf)
+// flush passes the last lines, when they lack a newline, to the line callbacks
+func (out *runOutput) flush() {
+	for _, line := range []*lineWriter{out.outLine, out.errLine} {
+		if line != nil {
+			line.flush()
+		}
+	}
+}
 
-	err = cmd.Start()
-	if err != nil {
-		*returnVar = 1
-		return ""
+// err returns the first error of the writers of RunOptions
+func (out *runOutput) err() error {
+	for _, w := range out.writers {
+		if w.err != nil {
+			return fmt.Errorf("writing output: %w", w.err)
+		}
 	}
+	return nil
+}
 
-	go func() {
-		_, err1 = io.Copy(stdout, stdoutIn)
-	}()
+type writerFunc func(p []byte) (int, error)
 
-	go func() {
-		_, err2 = io.Copy(stderr, stderrIn)
-	}()
+func (f writerFunc) Write(p []byte) (int, error) {
+	return f(p)
+}
 
-	err3 = cmd.Wait()
-	if err1 != nil || err2 != nil || err3 != nil {
-		if err1 != nil {
-			fmt.Println(err1)
+// errorWriter stops writing to w after its first error, which it keeps instead of returning it so
+// that the command does not see a broken pipe, unless w is a pipe whose reader has gone
+type errorWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (ew *errorWriter) Write(p []byte) (int, error) {
+	if ew.err == nil {
+		n, err := ew.w.Write(p)
+		if err == nil && n < len(p) {
+			err = io.ErrShortWrite
 		}
-		if err2 != nil {
-			fmt.Println(err2)
+		ew.err = err
+	}
+	if errors.Is(ew.err, io.ErrClosedPipe) || errors.Is(ew.err, syscall.EPIPE) {
+		return 0, ew.err
+	}
+	return len(p), nil
+}
+
+// boundedBuffer keeps the first head bytes written to it and the last max-head ones, all of them
+// when max is 0 and none when it is negative
+type boundedBuffer struct {
+	max, head int
+	buf, tail []byte
+	start     int // oldest byte of tail once it is full, tail being a ring
+	omitted   int64
+}
+
+func newBoundedBuffer(max, head int) *boundedBuffer {
+	if head > max {
+		head = max
+	}
+	if head < 0 {
+		head = 0
+	}
+	return &boundedBuffer{max: max, head: head}
+}
+
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	switch {
+	case b.max < 0:
+		b.omitted += int64(n)
+		return n, nil
+	case b.max == 0:
+		b.buf = append(b.buf, p...)
+		return n, nil
+	}
+
+	if room := b.head - len(b.buf); room > 0 {
+		if room > len(p) {
+			room = len(p)
 		}
-		if err3 != nil {
-			fmt.Println(err3)
+		b.buf, p = append(b.buf, p[:room]...), p[room:]
+	}
+
+	limit := b.max - b.head
+	if len(p) >= limit {
+		b.omitted += int64(len(b.tail) + len(p) - limit)
+		b.tail, b.start = append(b.tail[:0], p[len(p)-limit:]...), 0
+		return n, nil
+	}
+	if room := limit - len(b.tail); room > 0 {
+		if room > len(p) {
+			room = len(p)
 		}
-		*returnVar = 1
-		return ""
+		b.tail, p = append(b.tail, p[:room]...), p[room:]
 	}
+	// the ring is full: overwrite its oldest bytes
+	for len(p) > 0 {
+		copied := copy(b.tail[b.start:], p)
+		b.omitted += int64(copied)
+		b.start = (b.start + copied) % limit
+		p = p[copied:]
+	}
+	return n, nil
+}
+
+// Bytes returns the head and the tail kept
+func (b *boundedBuffer) Bytes() []byte {
+	if len(b.tail) == 0 {
+		return b.buf
+	}
+	out := append(b.buf[:len(b.buf):len(b.buf)], b.tail[b.start:]...)
+	return append(out, b.tail[:b.start]...)
+}
+
+// lineWriter calls fn with each line written to it, without its newline
+type lineWriter struct {
+	mu   *sync.Mutex // shared by stdout and stderr, so that the callbacks never run concurrently
+	fn   func(line string)
+	line []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 
-	if output := strings.TrimRight(stdBuf.String(), "\n"); output != "" {
-		pos := strings.LastIndex(output, "\n")
-		if pos == -1 {
-			return output
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.line = append(w.line, p...)
+			if len(w.line) >= maxLineLength {
+				w.emit()
+			}
+			break
 		}
-		return output[pos+1:]
+		w.line = append(w.line, p[:i]...)
+		w.emit()
+		p = p[i+1:]
 	}
+	return n, nil
+}
+
+func (w *lineWriter) flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.line) > 0 {
+		w.emit()
+	}
+}
+
+func (w *lineWriter) emit() {
+	w.fn(strings.TrimSuffix(string(w.line), "\r"))
+	w.line = w.line[:0]
+}
+
+// PrefixWriter — Wrap w so that every line written to it begins with prefix
+// RunOptions{Stdout: PrefixWriter(os.Stdout, "[build] ")}
+func PrefixWriter(w io.Writer, prefix string) io.Writer {
+	return &decoratedWriter{w: w, prefix: func() string { return prefix }, lineStart: true}
+}
 
-	return ""
+// TimestampWriter — Wrap w so that every line written to it begins with the time and a space
+// The time is read from the package clock when the line begins and formatted with a Go layout.
+// RunOptions{Stderr: TimestampWriter(os.Stderr, time.RFC3339)}
+func TimestampWriter(w io.Writer, layout string) io.Writer {
+	return &decoratedWriter{w: w, prefix: func() string { return getClock().Now().Format(layout) + " " }, lineStart: true}
+}
+
+type decoratedWriter struct {
+	mu        sync.Mutex
+	w         io.Writer
+	prefix    func() string
+	lineStart bool
+}
+
+func (d *decoratedWriter) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var b []byte
+	for _, line := range bytes.SplitAfter(p, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		if d.lineStart {
+			b = append(b, d.prefix()...)
+		}
+		b = append(b, line...)
+		d.lineStart = line[len(line)-1] == '\n'
+	}
+
+	if _, err := d.w.Write(b); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// lastLine returns the last line of out, ignoring trailing newlines
+func lastLine(out []byte) string {
+	s := strings.TrimRight(string(out), "\n")
+	return s[strings.LastIndex(s, "\n")+1:]
+}
+
+// Exec — Execute an external program
+// The command is run by Run. output receives the lines of its output, stdout and stderr together,
+// and returnVar its exit status.
+// Return the last line from the result of the command.
+func Exec(command string, output *[]string, returnVar *int) string {
+	return ExecContext(context.Background(), command, output, returnVar)
+}
+
+// ExecContext — Execute an external program until it exits or ctx is done
+// A canceled command is stopped as RunContext does, with the DefaultGracePeriod.
+func ExecContext(ctx context.Context, command string, output *[]string, returnVar *int) string {
+	r := RunContext(ctx, command, nil)
+	*returnVar = r.ExitCode
+
+	*output = nil
+	if out := strings.TrimRight(string(r.Combined), "\n"); out != "" {
+		*output = strings.Split(out, "\n")
+	}
+
+	return lastLine(r.Combined)
+}
+
+// System — Execute an external program and display the output
+// The command is run by Run, its output copied to os.Stdout and os.Stderr, and returnVar receives
+// its exit status.
+// Returns the last line of the command output.
+func System(command string, returnVar *int) string {
+	return SystemContext(context.Background(), command, returnVar)
+}
+
+// SystemContext — Execute an external program and display the output until it exits or ctx is done
+// A canceled command is stopped as RunContext does, with the DefaultGracePeriod.
+func SystemContext(ctx context.Context, command string, returnVar *int) string {
+	// only the last line is needed
+	r := RunContext(ctx, command, &RunOptions{Stdout: os.Stdout, Stderr: os.Stderr, MaxCapture: maxLineLength})
+	*returnVar = r.ExitCode
+	printStartError(r)
+
+	return lastLine(r.Combined)
 }
 
 // Passthru — Execute an external program and display raw output
-// returnVar, 0: succ; 1: fail
+// The command is run by Run, its output copied to os.Stdout and os.Stderr, and returnVar receives
+// its exit status.
 func Passthru(command string, returnVar *int) {
-	r, _ := regexp.Compile(`[ ]+`)
-	parts := r.Split(command, -1)
+	PassthruContext(context.Background(), command, returnVar)
+}
+
+// PassthruContext — Execute an external program and display raw output until it exits or ctx is done
+// A canceled command is stopped as RunContext does, with the DefaultGracePeriod.
+func PassthruContext(ctx context.Context, command string, returnVar *int) {
+	r := RunContext(ctx, command, &RunOptions{Stdout: os.Stdout, Stderr: os.Stderr, MaxCapture: -1})
+	*returnVar = r.ExitCode
+	printStartError(r)
+}
+
+// printStartError reports on os.Stderr, as a shell would, a command that could not run
+func printStartError(r *Result) {
+	var err *CommandError
+	if errors.As(r.Err, &err) && err.Err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+}
+
+// ErrShellDisabled — The error of RunShell and ShellExec while no shell is set, see SetShell
+var ErrShellDisabled = errors.New("running commands through a shell is disabled, see SetShell")
+
+var (
+	shellMu sync.RWMutex
+	shell   string
+)
+
+// SetShell — Set the shell of RunShell and ShellExec and return the previous one
+// Commands are run as shell -c command. No shell is set at first, so that running a command line
+// through a shell is an explicit choice; pass "" to disable it again.
+// defer SetShell(SetShell("/bin/sh"))
+func SetShell(path string) string {
+	shellMu.Lock()
+	defer shellMu.Unlock()
+
+	prev := shell
+	shell = path
+	return prev
+}
+
+// getShell returns the shell of RunShell, "" when disabled
+func getShell() string {
+	shellMu.RLock()
+	defer shellMu.RUnlock()
+	return shell
+}
+
+// RunShell — Execute a command line through the shell set by SetShell until it exits or ctx is done
+// Pipes, redirections and the other features of the shell are available, and the command is run
+// as RunContext does. Err wraps ErrShellDisabled when no shell is set.
+// defer SetShell(SetShell("/bin/sh"))
+// r := RunShell(ctx, "ps aux | grep nginx", nil)
+func RunShell(ctx context.Context, command string, opts *RunOptions) *Result {
+	sh := getShell()
+	if sh == "" {
+		return &Result{Command: command, ExitCode: 126, Err: &CommandError{Command: command, ExitCode: 126, Err: ErrShellDisabled}}
+	}
+	return getExecutor().Run(ctx, Command{Line: command, Args: []string{sh, "-c", command}}, opts)
+}
+
+// ShellExec — Execute command via shell and return the complete output as a string
+// It is the backtick operator of PHP: the command is run by RunShell, its stderr goes to os.Stderr
+// and its stdout is returned whatever its exit status. The error is only set when the command
+// could not run, such as when no shell is set.
+func ShellExec(command string) (string, error) {
+	r := RunShell(context.Background(), command, &RunOptions{Stderr: os.Stderr})
+
+	var err *CommandError
+	if errors.As(r.Err, &err) && err.Err != nil {
+		return string(r.Stdout), r.Err
+	}
+	return string(r.Stdout), nil
+}
+
+// Pipeline — Commands whose output feeds the input of the next one, as in a shell pipeline
+// No shell is involved: each command is split and run like RunContext does, and they run together.
+// When a command exits, the one before it gets a broken pipe on its next write.
+// r := NewPipeline("ps aux", "grep nginx").Run(ctx, nil)
+// r.PipeStatus() // []int{0, 1} when nginx does not run
+type Pipeline struct {
+	commands []string
+	// PipeFail makes the exit status of the pipeline the one of its last failing command, like
+	// bash's set -o pipefail
+	PipeFail bool
+}
+
+// PipelineResult — The outcome of a Pipeline
+// The embedded Result describes the whole pipeline: the Stdout of its last command, the Stderr of
+// all of them in order and the exit status of the last one, see Pipeline.PipeFail. A canceled
+// pipeline has the Err of a canceled command. Stages holds the Result of every command; the output
+// a command pipes to the next one is not captured, so only the last one has a Stdout.
+type PipelineResult struct {
+	Result
+	Stages []*Result
+}
+
+// PipeStatus — Return the exit statuses of the commands, like bash's PIPESTATUS
+func (r *PipelineResult) PipeStatus() []int {
+	status := make([]int, len(r.Stages))
+	for i, stage := range r.Stages {
+		status[i] = stage.ExitCode
+	}
+	return status
+}
+
+// NewPipeline — Create a pipeline of commands
+func NewPipeline(commands ...string) *Pipeline {
+	return &Pipeline{commands: commands}
+}
+
+// Pipe — Add a command reading the output of the last one
+func (p *Pipeline) Pipe(command string) *Pipeline {
+	p.commands = append(p.commands, command)
+	return p
+}
+
+// String — Return the pipeline as a shell would write it
+func (p *Pipeline) String() string {
+	return strings.Join(p.commands, " | ")
+}
+
+// Run — Execute the pipeline until all its commands exit or ctx is done
+// opts applies to the whole pipeline: the first command reads its Stdin, the last one writes to
+// its Stdout and all of them to its Stderr.
+func (p *Pipeline) Run(ctx context.Context, opts *RunOptions) *PipelineResult {
+	if opts == nil {
+		opts = &RunOptions{}
+	}
+	r := &PipelineResult{Result: Result{Command: p.String()}, Stages: make([]*Result, len(p.commands))}
+	if len(p.commands) == 0 {
+		r.ExitCode = 2
+		r.Err = &CommandError{ExitCode: 2, Err: errors.New("empty pipeline")}
+		return r
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	// the commands share the error output
+	shared := sharedOptions(opts)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	var in *io.PipeReader
+	for i, command := range p.commands {
+		stage := *shared
+		stage.Timeout = 0
+		if in != nil {
+			stage.Stdin = in
+		}
+
+		var out *io.PipeWriter
+		next := in
+		if i < len(p.commands)-1 {
+			next, out = io.Pipe()
+			stage.Stdout, stage.OnStdoutLine, stage.pipedStdout = out, nil, true
+		}
+
+		wg.Add(1)
+		go func(i int, command string, stage RunOptions, in *io.PipeReader, out *io.PipeWriter) {
+			defer wg.Done()
+			r.Stages[i] = RunContext(ctx, command, &stage)
+			if out != nil {
+				out.Close()
+			}
+			if in != nil {
+				in.Close()
+			}
+		}(i, command, stage, in, out)
+		in = next
+	}
+	wg.Wait()
+	r.Duration = time.Since(start)
 
-	var args []string
-	if len(parts) > 1 {
-		args = parts[1:]
+	last := r.Stages[len(r.Stages)-1]
+	r.Stdout, r.Omitted = last.Stdout, last.Omitted
+	for _, stage := range r.Stages[:len(r.Stages)-1] {
+		r.Stderr = append(r.Stderr, stage.Stderr...)
+		r.Combined = append(r.Combined, stage.Stderr...)
 	}
+	r.Stderr = append(r.Stderr, last.Stderr...)
+	r.Combined = append(r.Combined, last.Combined...)
 
-	cmd := exec.Command(parts[0], args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	status := last
+	if p.PipeFail {
+		for i := len(r.Stages) - 1; i >= 0; i-- {
+			if r.Stages[i].ExitCode != 0 {
+				status = r.Stages[i]
+				break
+			}
+		}
+	}
+	r.ExitCode, r.Signal, r.Err = status.ExitCode, status.Signal, status.Err
+
+	if err := ctx.Err(); err != nil && !errors.Is(r.Err, err) {
+		for _, stage := range r.Stages {
+			if errors.Is(stage.Err, err) {
+				r.Err = stage.Err
+				break
+			}
+		}
+	}
+	return r
+}
+
+// sharedOptions returns a copy of opts whose writers and line callbacks can be used by commands
+// running together, one at a time
+func sharedOptions(opts *RunOptions) *RunOptions {
+	var mu sync.Mutex
+	shared := *opts
+	locked := func(w io.Writer) io.Writer {
+		return writerFunc(func(p []byte) (int, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return w.Write(p)
+		})
+	}
+	lockedLine := func(fn func(string)) func(string) {
+		return func(line string) {
+			mu.Lock()
+			defer mu.Unlock()
+			fn(line)
+		}
+	}
+
+	if opts.Stdout != nil {
+		shared.Stdout = locked(opts.Stdout)
+	}
+	if opts.Stderr != nil {
+		shared.Stderr = locked(opts.Stderr)
+	}
+	if opts.OnStdoutLine != nil {
+		shared.OnStdoutLine = lockedLine(opts.OnStdoutLine)
+	}
+	if opts.OnStderrLine != nil {
+		shared.OnStderrLine = lockedLine(opts.OnStderrLine)
+	}
+	return &shared
+}
 
-	err := cmd.Run()
+// ProcOptions — Settings of ProcOpen
+// Env replaces the environment of the process, nil keeps the one of this process. ExtraFiles
+// become its file descriptors 3, 4 and so on. Stdin, Stdout and Stderr, when set, are used instead
+// of a pipe, the matching field of Process then being nil.
+type ProcOptions struct {
+	Dir        string
+	Env        []string
+	ExtraFiles []*os.File
+	Stdin      io.Reader
+	Stdout     io.Writer
+	Stderr     io.Writer
+}
+
+// Process — A program started by ProcOpen
+// Stdin, Stdout and Stderr are the ends of its pipes; closing Stdin signals the end of the input.
+type Process struct {
+	Stdin   io.WriteCloser
+	Stdout  io.ReadCloser
+	Stderr  io.ReadCloser
+	command string
+	cmd     *exec.Cmd
+	pipes   []*os.File
+	done    chan struct{}
+}
+
+// ProcStatus — The state of a process, see ProcGetStatus
+// ExitCode follows Result.ExitCode and is -1 while the process runs.
+type ProcStatus struct {
+	Command  string
+	Pid      int
+	Running  bool
+	Signaled bool
+	ExitCode int
+	TermSig  syscall.Signal
+}
+
+// ProcOpen — Execute a command and open file pointers for input/output
+// The command is split like Run does and runs in its own process group. Its errors are *CommandError.
+// p, _ := ProcOpen("cat", nil)
+// p.Stdin.Write([]byte("hello"))
+// p.Stdin.Close()
+// out, _ := io.ReadAll(p.Stdout)
+// ProcClose(p) == 0
+func ProcOpen(command string, opts *ProcOptions) (*Process, error) {
+	if opts == nil {
+		opts = &ProcOptions{}
+	}
+
+	cmd, err := shellCommand(command)
 	if err != nil {
-		*returnVar = 1
-		fmt.Println(err)
-	} else {
-		*returnVar = 0
+		return nil, &CommandError{Command: command, ExitCode: 2, Err: err}
+	}
+	cmd.Dir, cmd.Env, cmd.ExtraFiles = opts.Dir, opts.Env, opts.ExtraFiles
+	setProcessGroup(cmd)
+
+	// the pipes are made here rather than by exec.Cmd, whose Wait would close them before they are read
+	p := &Process{command: command, cmd: cmd, done: make(chan struct{})}
+	var childEnds []*os.File
+	closeAll := func() {
+		for _, f := range append(childEnds, p.pipes...) {
+			_ = f.Close()
+		}
+	}
+	pipe := func(parentWrites bool) (parent, child *os.File, err error) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, nil, err
+		}
+		if parentWrites {
+			parent, child = w, r
+		} else {
+			parent, child = r, w
+		}
+		p.pipes, childEnds = append(p.pipes, parent), append(childEnds, child)
+		return parent, child, nil
+	}
+
+	if cmd.Stdin = opts.Stdin; cmd.Stdin == nil {
+		p.Stdin, cmd.Stdin, err = pipe(true)
+	}
+	if cmd.Stdout = opts.Stdout; cmd.Stdout == nil && err == nil {
+		p.Stdout, cmd.Stdout, err = pipe(false)
+	}
+	if cmd.Stderr = opts.Stderr; cmd.Stderr == nil && err == nil {
+		p.Stderr, cmd.Stderr, err = pipe(false)
+	}
+	if err != nil {
+		closeAll()
+		return nil, &CommandError{Command: command, ExitCode: -1, Err: err}
+	}
+
+	if err = cmd.Start(); err != nil {
+		closeAll()
+		code := 126
+		if errors.Is(err, exec.ErrNotFound) || errors.Is(err, os.ErrNotExist) {
+			code = 127
+		}
+		return nil, &CommandError{Command: command, ExitCode: code, Err: err}
+	}
+	for _, f := range childEnds {
+		_ = f.Close()
+	}
+
+	go func() {
+		_ = cmd.Wait()
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// ProcGetStatus — Get information about a process opened by ProcOpen
+func ProcGetStatus(p *Process) *ProcStatus {
+	status := &ProcStatus{Command: p.command, Pid: p.cmd.Process.Pid, Running: true, ExitCode: -1}
+
+	select {
+	case <-p.done:
+	default:
+		return status
+	}
+
+	status.Running = false
+	status.ExitCode = p.cmd.ProcessState.ExitCode()
+	if ws, ok := p.cmd.ProcessState.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		status.Signaled, status.TermSig = true, ws.Signal()
+		status.ExitCode = 128 + int(status.TermSig)
+	}
+	return status
+}
+
+// ProcTerminate — Kills a process opened by ProcOpen
+// The signal, usually syscall.SIGTERM, is sent to its process group. It returns os.ErrProcessDone
+// when the process has already exited.
+func ProcTerminate(p *Process, signal syscall.Signal) error {
+	select {
+	case <-p.done:
+		return os.ErrProcessDone
+	default:
+	}
+	return signalProcessGroup(p.cmd, signal)
+}
+
+// ProcClose — Close a process opened by ProcOpen and return the exit code of that process
+// The pipes are closed first, so output left unread is lost, then the process is waited for.
+// The exit code follows Result.ExitCode.
+func ProcClose(p *Process) int {
+	for _, f := range p.pipes {
+		_ = f.Close()
+	}
+	<-p.done
+	return ProcGetStatus(p).ExitCode
+}
+
+// splitCommand splits command like a POSIX shell, expanding the environment
+func splitCommand(command string) ([]string, error) {
+	args, err := ShellSplit(command, os.Getenv)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, errors.New("empty command")
+	}
+	return args, nil
+}
+
+// shellCommand splits command like a POSIX shell, expanding the environment, into an exec.Cmd
+func shellCommand(command string) (*exec.Cmd, error) {
+	args, err := splitCommand(command)
+	if err != nil {
+		return nil, err
+	}
+	return exec.Command(args[0], args[1:]...), nil
+}
+
+// ShellSplit — Split a command line into words like a POSIX shell
+// Words are separated by spaces, tabs and newlines. Single quotes keep everything up to the next
+// single quote; double quotes keep everything but the escapes \$, \`, \", \\ and \newline and the
+// expansions; a backslash outside quotes escapes the next character, and a # starting a word
+// begins a comment. When getenv is not nil, $NAME and ${NAME} are replaced by its result, the
+// unquoted ones being split into words on blanks, and the positional parameters are empty.
+// Operators such as |, ;, > and command substitution are refused with an error; globs are not
+// expanded.
+// ShellSplit(`grep 'hello world' "$HOME/file.txt"`, os.Getenv) == []string{"grep", "hello world", "/home/go/file.txt"}
+func ShellSplit(command string, getenv func(string) string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false  // the word is kept, if only as ""
+	started := false // a word has begun, even one expanding to nothing
+	flush := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		begins := !started
+		started = !isShellBlank(c)
+		switch {
+		case isShellBlank(c):
+			flush()
+		case c == '#' && begins:
+			for i+1 < len(command) && command[i+1] != '\n' {
+				i++
+			}
+		case c == '\\':
+			if i+1 == len(command) {
+				return nil, fmt.Errorf("unterminated escape at position %d", i)
+			}
+			i++
+			if command[i] != '\n' {
+				word.WriteByte(command[i])
+				inWord = true
+			}
+		case c == '\'':
+			end := strings.IndexByte(command[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote at position %d", i)
+			}
+			word.WriteString(command[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+		case c == '"':
+			start := i
+			inWord = true
+			for i++; ; i++ {
+				if i == len(command) {
+					return nil, fmt.Errorf("unterminated double quote at position %d", start)
+				}
+
+				c := command[i]
+				if c == '"' {
+					break
+				}
+				switch {
+				case c == '\\' && i+1 < len(command) && strings.IndexByte("$`\"\\\n", command[i+1]) >= 0:
+					i++
+					if command[i] != '\n' {
+						word.WriteByte(command[i])
+					}
+				case c == '`':
+					return nil, fmt.Errorf("command substitution at position %d is not supported", i)
+				case c == '$' && getenv != nil:
+					value, n, err := shellExpand(command[i:], getenv)
+					if err != nil {
+						return nil, fmt.Errorf("%v at position %d", err, i)
+					}
+					word.WriteString(value)
+					i += n - 1
+				default:
+					word.WriteByte(c)
+				}
+			}
+		case c == '$' && getenv != nil:
+			value, n, err := shellExpand(command[i:], getenv)
+			if err != nil {
+				return nil, fmt.Errorf("%v at position %d", err, i)
+			}
+			if n == 1 {
+				word.WriteByte(c)
+				inWord = true
+				continue
+			}
+
+			// field splitting: every blank ends a word
+			for j := 0; j < len(value); j++ {
+				if isShellBlank(value[j]) {
+					flush()
+				} else {
+					word.WriteByte(value[j])
+					inWord = true
+				}
+			}
+			i += n - 1
+		case strings.IndexByte("|&;<>()`", c) >= 0:
+			return nil, fmt.Errorf("shell operator %q at position %d is not supported", c, i)
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+
+	flush()
+	return words, nil
+}
+
+func isShellBlank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n'
+}
+
+// shellExpand expands the parameter at the start of s, which begins with $, and returns its value
+// and length. Positional parameters are empty and a $ not followed by a name is kept as is, with a
+// length of 1.
+func shellExpand(s string, getenv func(string) string) (string, int, error) {
+	if strings.HasPrefix(s, "$(") {
+		return "", 0, errors.New("command substitution is not supported")
+	}
+
+	if strings.HasPrefix(s, "${") {
+		end := strings.IndexByte(s, '}')
+		if end < 0 {
+			return "", 0, errors.New("unterminated ${")
+		}
+		name := s[2:end]
+		switch {
+		case name != "" && shellNameLen(name) == len(name):
+			return getenv(name), end + 1, nil
+		case name != "" && strings.Trim(name, "0123456789") == "":
+			return "", end + 1, nil
+		}
+		return "", 0, fmt.Errorf("bad substitution %q", s[:end+1])
+	}
+
+	if n := shellNameLen(s[1:]); n > 0 {
+		return getenv(s[1 : n+1]), n + 1, nil
+	}
+	if len(s) > 1 {
+		switch c := s[1]; {
+		case c >= '0' && c <= '9':
+			// there are no positional parameters
+			return "", 2, nil
+		case strings.IndexByte("@*#?-$!", c) >= 0:
+			return "", 0, fmt.Errorf("special parameter $%c is not supported", c)
+		}
+	}
+	return "$", 1, nil
+}
+
+// shellNameLen returns the length of the variable name at the start of s
+func shellNameLen(s string) int {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (i == 0 || c < '0' || c > '9') {
+			return i
+		}
+	}
+	return len(s)
+}
+
+// EscapeShellArg — Escape a string to be used as a shell argument
+// The string is single quoted, so that ShellSplit and sh read it back unchanged.
+// EscapeShellArg("a b") == "'a b'"
+func EscapeShellArg(arg string) string {
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
+}
+
+// EscapeShellCmd — Escape shell metacharacters
+// #&;`|*?~<>^()[]{}$\, newline and \xFF are preceded by a backslash, and so are quotes unless
+// they come in pairs, as PHP does. Arguments should be escaped with EscapeShellArg instead.
+// EscapeShellCmd("ls; rm -rf *") == `ls\; rm -rf \*`
+func EscapeShellCmd(command string) string {
+	var b strings.Builder
+	pair := -1 // position of the quote closing the open one
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case c == '\'' || c == '"':
+			if pair < 0 {
+				if end := strings.IndexByte(command[i+1:], c); end >= 0 {
+					pair = i + 1 + end
+					b.WriteByte(c)
+					continue
+				}
+			} else if i == pair {
+				pair = -1
+				b.WriteByte(c)
+				continue
+			}
+			b.WriteByte('\\')
+		case strings.IndexByte("#&;`|*?~<>^()[]{}$\\\n\xff", c) >= 0:
+			b.WriteByte('\\')
+		}
+		b.WriteByte(c)
 	}
+	return b.String()
 }
@@ -0,0 +1,16 @@
+//go:build !unix
+
+package utils
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup does nothing where process groups cannot be signaled
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalProcessGroup kills the process of cmd whatever sig is, the only signal available everywhere
+func signalProcessGroup(cmd *exec.Cmd, sig syscall.Signal) error {
+	return cmd.Process.Kill()
+}
@@ -1,7 +1,20 @@
 package utils
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestProgramExecution(t *testing.T) {
@@ -19,3 +32,380 @@ func TestProgramExecution(t *testing.T) {
 	Passthru("echo hello", &retVal)
 	equal(t, 0, retVal)
 }
+
+func TestShellSplit(t *testing.T) {
+	t.Setenv("SPLIT_NAME", "hello world")
+	t.Setenv("SPLIT_EMPTY", "")
+
+	for command, expected := range map[string][]string{
+		"":                                   nil,
+		"  # only a comment":                 nil,
+		"grep 'hello world' file.txt":        {"grep", "hello world", "file.txt"},
+		"a\t b  \t c":                        {"a", "b", "c"},
+		`a\ b c\\d \'e`:                      {"a b", `c\d`, "'e"},
+		`"a \"b\" \$c \\ \d" 'x\y'`:          {`a "b" $c \ \d`, `x\y`},
+		`"" '' x""y`:                         {"", "", "xy"},
+		"a#b # comment":                      {"a#b"},
+		"echo $SPLIT_NAME":                   {"echo", "hello", "world"},
+		`echo "$SPLIT_NAME" x${SPLIT_NAME}y`: {"echo", "hello world", "xhello", "worldy"},
+		`echo $SPLIT_EMPTY "$SPLIT_EMPTY"`:   {"echo", ""},
+		"$SPLIT_EMPTY#x $SPLIT_EMPTY #y":     {"#x"},
+		"$SPLIT_NAME#x":                      {"hello", "world#x"},
+		`cost $ 5 $5 '$SPLIT_NAME' ${12}`:    {"cost", "$", "5", "$SPLIT_NAME"},
+		"line\\\ncontinued":                  {"linecontinued"},
+		"a\nb":                               {"a", "b"},
+	} {
+		words, err := ShellSplit(command, os.Getenv)
+		equal(t, nil, err)
+		equal(t, expected, words)
+
+		// the same words as sh
+		if _, err := os.Stat("/bin/sh"); err == nil && !strings.Contains(command, "\n") {
+			script := "set -- " + command + "\nfor a do printf '%s\\0' \"$a\"; done"
+			out, err := exec.Command("/bin/sh", "-c", script).Output()
+			equal(t, nil, err)
+			var sh []string
+			if len(out) > 0 {
+				sh = strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00")
+			}
+			equal(t, sh, words)
+		}
+	}
+
+	words, _ := ShellSplit(`echo $HOME "${HOME}"`, nil)
+	equal(t, []string{"echo", "$HOME", "${HOME}"}, words)
+
+	for _, command := range []string{`'open`, `"open`, `end\`, "ls | wc", "a; b", "a > b", "a && b", "(a)", "echo `id`", `echo "$(id)"`, `echo ${A`, `echo ${A-b}`, "echo $$"} {
+		_, err := ShellSplit(command, os.Getenv)
+		unequal(t, nil, err)
+	}
+
+	var output []string
+	var retVal int
+	equal(t, "hello world", Exec(`printf '%s\n' "hello world"`, &output, &retVal))
+	equal(t, 0, retVal)
+	equal(t, "", Exec("printf 'unterminated", &output, &retVal))
+	equal(t, 2, retVal)
+	equal(t, "", System("   ", &retVal))
+	equal(t, 2, retVal)
+
+	equal(t, "'a b'", EscapeShellArg("a b"))
+	equal(t, `'it'\''s'`, EscapeShellArg("it's"))
+	equal(t, `ls\; rm -rf \*`, EscapeShellCmd("ls; rm -rf *"))
+	equal(t, `echo 'a\;b' \"c`, EscapeShellCmd(`echo 'a;b' "c`))
+	equal(t, `'\"'\"`, EscapeShellCmd(`'"'"`))
+}
+
+func FuzzEscapeShellArg(f *testing.F) {
+	for _, seed := range []string{"", "a b", "it's", `"$HOME"`, "\\", "'''", "a\nb", "\t", "#", "\xff"} {
+		f.Add(seed, "x")
+	}
+
+	f.Fuzz(func(t *testing.T, a, b string) {
+		words, err := ShellSplit(EscapeShellArg(a)+" "+EscapeShellArg(b), os.Getenv)
+		if err != nil || len(words) != 2 || words[0] != a || words[1] != b {
+			t.Errorf("%q and %q read back as %q, %v", a, b, words, err)
+		}
+	})
+}
+
+func FuzzEscapeShellCmd(f *testing.F) {
+	for _, seed := range []string{"ls -l", "ls; rm -rf *", `echo 'a;b' "c`, `'"'"`, "$(id) `id` ${X}", "a\\\nb", "#!x", "\xff"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, command string) {
+		escaped := EscapeShellCmd(command)
+		words, err := ShellSplit(escaped, nil)
+		if err != nil {
+			t.Fatalf("%q escaped as %q does not split: %v", command, escaped, err)
+		}
+
+		// without quotes and backslashes the words are those of the command
+		if !strings.ContainsAny(command, "'\"\\\n") {
+			fields := strings.FieldsFunc(command, func(r rune) bool { return r == ' ' || r == '\t' })
+			if len(fields) == 0 {
+				fields = nil
+			}
+			if !reflect.DeepEqual(fields, words) {
+				t.Errorf("%q escaped as %q splits into %q", command, escaped, words)
+			}
+		}
+	})
+}
+
+func TestRun(t *testing.T) {
+	r := Run(`sh -c 'echo out; echo err >&2'`)
+	equal(t, true, r.Success())
+	equal(t, 0, r.ExitCode)
+	equal(t, "out\n", string(r.Stdout))
+	equal(t, "err\n", string(r.Stderr))
+	equal(t, 8, len(r.Combined))
+	gt(t, float64(r.Duration), 0)
+
+	r = Run(`sh -c 'echo partial; exit 3'`)
+	equal(t, false, r.Success())
+	equal(t, 3, r.ExitCode)
+	equal(t, "partial\n", string(r.Stdout))
+	var err *CommandError
+	equal(t, true, errors.As(r.Err, &err))
+	equal(t, 3, err.ExitCode)
+	equal(t, `sh -c 'echo partial; exit 3': exit status 3`, err.Error())
+
+	r = Run(`sh -c 'kill -TERM $$'`)
+	equal(t, syscall.SIGTERM, r.Signal)
+	equal(t, 128+15, r.ExitCode)
+
+	r = Run("no-such-command-for-run")
+	equal(t, 127, r.ExitCode)
+	equal(t, true, errors.Is(r.Err, exec.ErrNotFound))
+	equal(t, 126, Run("./exec.go").ExitCode)
+	equal(t, 2, Run("echo 'unterminated").ExitCode)
+	equal(t, 2, Run("").ExitCode)
+
+	// the legacy functions report the real exit status and keep the output of a failure
+	var output []string
+	var retVal int
+	equal(t, "partial", Exec(`sh -c 'echo first; echo partial; exit 2'`, &output, &retVal))
+	equal(t, 2, retVal)
+	equal(t, []string{"first", "partial"}, output)
+	Exec("true", &output, &retVal)
+	equal(t, []string(nil), output)
+	equal(t, 0, retVal)
+	equal(t, "", System("no-such-command-for-run", &retVal))
+	equal(t, 127, retVal)
+	Passthru("sh -c 'exit 4'", &retVal)
+	equal(t, 4, retVal)
+}
+
+func TestRunContext(t *testing.T) {
+	r := RunContext(context.Background(), "sleep 10", &RunOptions{Timeout: 100 * time.Millisecond})
+	equal(t, true, errors.Is(r.Err, context.DeadlineExceeded))
+	equal(t, syscall.SIGTERM, r.Signal)
+	equal(t, 128+15, r.ExitCode)
+	rangeValue(t, float64(100*time.Millisecond), float64(5*time.Second), float64(r.Duration))
+
+	// a program ignoring SIGTERM is killed after the grace period
+	r = RunContext(context.Background(), `sh -c 'trap "" TERM; sleep 10'`, &RunOptions{Timeout: 100 * time.Millisecond, GracePeriod: 200 * time.Millisecond})
+	equal(t, true, errors.Is(r.Err, context.DeadlineExceeded))
+	equal(t, syscall.SIGKILL, r.Signal)
+	rangeValue(t, float64(300*time.Millisecond), float64(5*time.Second), float64(r.Duration))
+
+	r = RunContext(context.Background(), "sleep 10", &RunOptions{Timeout: 100 * time.Millisecond, GracePeriod: -1})
+	equal(t, syscall.SIGKILL, r.Signal)
+
+	// the whole process group is stopped: the background sleep holding stdout would otherwise keep
+	// the command waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	r = RunContext(ctx, `sh -c 'sleep 30 & sleep 30; echo done'`, nil)
+	equal(t, true, errors.Is(r.Err, context.Canceled))
+	equal(t, "", string(r.Stdout))
+	rangeValue(t, float64(100*time.Millisecond), float64(5*time.Second), float64(r.Duration))
+
+	r = RunContext(ctx, "true", nil)
+	equal(t, -1, r.ExitCode)
+	equal(t, true, errors.Is(r.Err, context.Canceled))
+
+	// a command finishing in time is not reported as canceled
+	r = RunContext(context.Background(), "true", &RunOptions{Timeout: time.Minute})
+	equal(t, nil, r.Err)
+
+	var output []string
+	var retVal int
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	ExecContext(ctx, `sh -c 'echo started; sleep 10'`, &output, &retVal)
+	equal(t, 128+15, retVal)
+	equal(t, []string{"started"}, output)
+
+	// the grace timer is released once the program exits on SIGTERM
+	clock := NewFakeClock(time.Now())
+	defer SetClock(SetClock(clock))
+	r = RunContext(context.Background(), "sleep 10", &RunOptions{Timeout: 100 * time.Millisecond, GracePeriod: time.Hour})
+	equal(t, syscall.SIGTERM, r.Signal)
+	equal(t, 0, clock.Waiters())
+}
+
+func TestRunOutput(t *testing.T) {
+	var stdout bytes.Buffer
+	var lines []string
+	r := RunContext(context.Background(), `sh -c 'echo one; echo two >&2; printf three'`, &RunOptions{
+		Stdout:       &stdout,
+		OnStdoutLine: func(line string) { lines = append(lines, "out:"+line) },
+		OnStderrLine: func(line string) { lines = append(lines, "err:"+line) },
+	})
+	equal(t, nil, r.Err)
+	equal(t, "one\nthree", stdout.String())
+	sort.Strings(lines)
+	equal(t, []string{"err:two", "out:one", "out:three"}, lines)
+
+	// bounded capture keeps the head and the tail
+	r = RunContext(context.Background(), "printf 0123456789abcdefghij", &RunOptions{MaxCapture: 8, HeadCapture: 3})
+	equal(t, "012fghij", string(r.Stdout))
+	equal(t, "012fghij", string(r.Combined))
+	equal(t, int64(12), r.Omitted)
+	r = RunContext(context.Background(), "printf 0123456789abcdefghij", &RunOptions{MaxCapture: 4})
+	equal(t, "ghij", string(r.Stdout))
+	r = RunContext(context.Background(), "head -c 1000000 /dev/zero", &RunOptions{MaxCapture: -1})
+	equal(t, 0, len(r.Stdout))
+	equal(t, int64(1000000), r.Omitted)
+
+	// across many small writes of varying sizes
+	var all []byte
+	bounded := newBoundedBuffer(100, 10)
+	for i := 0; i < 5000; i++ {
+		chunk := bytes.Repeat([]byte{byte('a' + i%26)}, i%7)
+		all = append(all, chunk...)
+		bounded.Write(chunk)
+		if i%500 == 0 || i == 4999 {
+			expected := all
+			if len(all) > 100 {
+				expected = append(append([]byte{}, all[:10]...), all[len(all)-90:]...)
+			}
+			equal(t, string(expected), string(bounded.Bytes()))
+			equal(t, int64(len(all)-len(expected)), bounded.omitted)
+		}
+	}
+	bounded.Write(bytes.Repeat([]byte("z"), 200))
+	equal(t, string(all[:10])+strings.Repeat("z", 90), string(bounded.Bytes()))
+	bounded = newBoundedBuffer(5, 5)
+	bounded.Write([]byte("0123456789"))
+	equal(t, "01234", string(bounded.Bytes()))
+	equal(t, int64(5), bounded.omitted)
+
+	// a failing writer does not stop the command, its error is reported
+	errFull := errors.New("disk full")
+	failing := writerFunc(func(p []byte) (int, error) { return 0, errFull })
+	r = RunContext(context.Background(), "head -c 1000000 /dev/zero", &RunOptions{Stdout: failing})
+	equal(t, 0, r.ExitCode)
+	equal(t, 1000000, len(r.Stdout))
+	equal(t, true, errors.Is(r.Err, errFull))
+
+	var b bytes.Buffer
+	w := PrefixWriter(&b, "> ")
+	fmt.Fprint(w, "a\nb")
+	fmt.Fprint(w, "c\n\nd")
+	equal(t, "> a\n> bc\n> \n> d", b.String())
+
+	defer SetClock(SetClock(NewFakeClock(time.Date(2018, 4, 27, 10, 23, 14, 0, time.UTC))))
+	b.Reset()
+	w = TimestampWriter(&b, "15:04:05")
+	fmt.Fprint(w, "x\ny\n")
+	equal(t, "10:23:14 x\n10:23:14 y\n", b.String())
+}
+
+func TestProcOpen(t *testing.T) {
+	p, err := ProcOpen("cat", nil)
+	equal(t, nil, err)
+	equal(t, true, ProcGetStatus(p).Running)
+	equal(t, -1, ProcGetStatus(p).ExitCode)
+	fmt.Fprint(p.Stdin, "hello\nworld\n")
+	p.Stdin.Close()
+	out, _ := io.ReadAll(p.Stdout)
+	equal(t, "hello\nworld\n", string(out))
+	equal(t, 0, ProcClose(p))
+	status := ProcGetStatus(p)
+	equal(t, false, status.Running)
+	equal(t, "cat", status.Command)
+	gt(t, float64(status.Pid), 0)
+
+	// working directory, environment and stderr
+	dir := t.TempDir()
+	p, err = ProcOpen(`sh -c 'pwd; echo "$GREETING"; echo oops >&2; exit 3'`, &ProcOptions{Dir: dir, Env: []string{"GREETING=hi"}})
+	equal(t, nil, err)
+	out, _ = io.ReadAll(p.Stdout)
+	errOut, _ := io.ReadAll(p.Stderr)
+	real, _ := filepath.EvalSymlinks(dir)
+	equal(t, real+"\nhi\n", string(out))
+	equal(t, "oops\n", string(errOut))
+	equal(t, 3, ProcClose(p))
+
+	// extra descriptors and redirections instead of pipes
+	r, w, _ := os.Pipe()
+	var stdout bytes.Buffer
+	p, err = ProcOpen(`sh -c 'cat; echo extra >&3'`, &ProcOptions{ExtraFiles: []*os.File{w}, Stdin: strings.NewReader("in\n"), Stdout: &stdout})
+	equal(t, nil, err)
+	w.Close()
+	equal(t, true, p.Stdin == nil)
+	equal(t, true, p.Stdout == nil)
+	extra, _ := io.ReadAll(r)
+	equal(t, "extra\n", string(extra))
+	equal(t, 0, ProcClose(p))
+	equal(t, "in\n", stdout.String())
+
+	p, err = ProcOpen("sleep 30", nil)
+	equal(t, nil, err)
+	equal(t, nil, ProcTerminate(p, syscall.SIGTERM))
+	equal(t, 128+15, ProcClose(p))
+	status = ProcGetStatus(p)
+	equal(t, true, status.Signaled)
+	equal(t, syscall.SIGTERM, status.TermSig)
+	equal(t, os.ErrProcessDone, ProcTerminate(p, syscall.SIGTERM))
+
+	_, err = ProcOpen("no-such-command-for-proc", nil)
+	var cmdErr *CommandError
+	equal(t, true, errors.As(err, &cmdErr))
+	equal(t, 127, cmdErr.ExitCode)
+}
+
+func TestShellExec(t *testing.T) {
+	_, err := ShellExec("echo hi")
+	equal(t, true, errors.Is(err, ErrShellDisabled))
+	equal(t, 126, RunShell(context.Background(), "echo hi", nil).ExitCode)
+
+	defer SetShell(SetShell("/bin/sh"))
+	out, err := ShellExec("echo abc | tr a-c x-z")
+	equal(t, nil, err)
+	equal(t, "xyz\n", out)
+	out, err = ShellExec("echo partial; exit 3")
+	equal(t, nil, err)
+	equal(t, "partial\n", out)
+	equal(t, 3, RunShell(context.Background(), "exit 3", nil).ExitCode)
+	equal(t, "/bin/sh", SetShell("/bin/sh"))
+}
+
+func TestPipeline(t *testing.T) {
+	r := NewPipeline(`printf 'b\na\nc\n'`, "sort").Pipe("head -n 2").Run(context.Background(), nil)
+	equal(t, nil, r.Err)
+	equal(t, "a\nb\n", string(r.Stdout))
+	equal(t, []int{0, 0, 0}, r.PipeStatus())
+	equal(t, `printf 'b\na\nc\n' | sort | head -n 2`, r.Command)
+
+	// the writer gets a broken pipe once the reader is gone
+	r = NewPipeline("yes", "head -n 1").Run(context.Background(), &RunOptions{Timeout: 10 * time.Second})
+	equal(t, "y\n", string(r.Stdout))
+	equal(t, []int{128 + 13, 0}, r.PipeStatus())
+	equal(t, syscall.SIGPIPE, r.Stages[0].Signal)
+	equal(t, nil, r.Err)
+
+	p := NewPipeline(`sh -c 'echo e1 >&2; echo out; exit 3'`, `sh -c 'cat; echo e2 >&2'`)
+	r = p.Run(context.Background(), nil)
+	equal(t, 0, r.ExitCode)
+	equal(t, []int{3, 0}, r.PipeStatus())
+	equal(t, "out\n", string(r.Stdout))
+	equal(t, "e1\ne2\n", string(r.Stderr))
+	equal(t, 0, len(r.Stages[0].Stdout)) // piped to cat, not captured
+	equal(t, "e1\n", string(r.Stages[0].Combined))
+	equal(t, "out\n", string(r.Stages[1].Stdout))
+	p.PipeFail = true
+	r = p.Run(context.Background(), nil)
+	equal(t, 3, r.ExitCode)
+	var err *CommandError
+	equal(t, true, errors.As(r.Err, &err))
+	equal(t, `sh -c 'echo e1 >&2; echo out; exit 3'`, err.Command)
+
+	r = NewPipeline("no-such-command-for-pipe", "cat").Run(context.Background(), nil)
+	equal(t, []int{127, 0}, r.PipeStatus())
+
+	var lines []string
+	r = NewPipeline("tr a-z A-Z").Run(context.Background(), &RunOptions{Stdin: strings.NewReader("one\ntwo\n"), OnStdoutLine: func(line string) { lines = append(lines, line) }})
+	equal(t, []string{"ONE", "TWO"}, lines)
+
+	r = NewPipeline("sleep 10", "cat").Run(context.Background(), &RunOptions{Timeout: 100 * time.Millisecond})
+	equal(t, true, errors.Is(r.Err, context.DeadlineExceeded))
+	rangeValue(t, 0, float64(5*time.Second), float64(r.Duration))
+
+	r = NewPipeline().Run(context.Background(), nil)
+	equal(t, 2, r.ExitCode)
+}
@@ -0,0 +1,221 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Command — A command handed to an Executor
+// Line is the command line as the caller wrote it and Args the program and its arguments.
+type Command struct {
+	Line string
+	Args []string
+}
+
+// Executor — Runs the commands of RunContext, RunShell, pipelines and the functions built on them
+// such as Exec, System, Passthru and ShellExec. ProcOpen always starts a real process.
+// Run follows RunContext: it honours ctx and opts and reports its failures in Result.Err.
+type Executor interface {
+	Run(ctx context.Context, cmd Command, opts *RunOptions) *Result
+}
+
+type realExecutor struct{}
+
+func (realExecutor) Run(ctx context.Context, cmd Command, opts *RunOptions) *Result {
+	return runCommand(ctx, cmd.Line, exec.Command(cmd.Args[0], cmd.Args[1:]...), opts)
+}
+
+// NewRealExecutor — Create an executor starting processes with os/exec
+func NewRealExecutor() Executor {
+	return realExecutor{}
+}
+
+var (
+	executorMu sync.RWMutex
+	executor   Executor = realExecutor{}
+)
+
+// SetExecutor — Replace the package executor and return the previous one
+// Passing nil restores the real executor.
+// Usage in tests:
+// defer SetExecutor(SetExecutor(NewFakeExecutor()))
+func SetExecutor(e Executor) Executor {
+	if e == nil {
+		e = realExecutor{}
+	}
+
+	executorMu.Lock()
+	defer executorMu.Unlock()
+
+	prev := executor
+	executor = e
+	return prev
+}
+
+// getExecutor returns the package executor
+func getExecutor() Executor {
+	executorMu.RLock()
+	defer executorMu.RUnlock()
+	return executor
+}
+
+// FakeResponse — The canned outcome of a command run by a FakeExecutor
+// The command takes Delay, on the package clock, before it answers. Signal, when set, reports it as
+// killed by that signal. Err, such as exec.ErrNotFound, makes it a command that could not run.
+type FakeResponse struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Signal   syscall.Signal
+	Delay    time.Duration
+	Err      error
+}
+
+// FakeCall — A command received by a FakeExecutor, with what it read from its stdin
+type FakeCall struct {
+	Command
+	Stdin string
+}
+
+// FakeExecutor — An executor answering the commands registered with Expect without running anything
+// A command line is matched exactly, and an unexpected one exits with status 127. The commands of
+// a pipeline run together, so their order in Calls is not defined. It is safe for concurrent use.
+// fake := NewFakeExecutor().Expect("git rev-parse HEAD", FakeResponse{Stdout: "1a2b3c\n"})
+// defer SetExecutor(SetExecutor(fake))
+type FakeExecutor struct {
+	mu        sync.Mutex
+	responses map[string][]FakeResponse
+	calls     []FakeCall
+}
+
+// NewFakeExecutor — Create an executor expecting no command
+func NewFakeExecutor() *FakeExecutor {
+	return &FakeExecutor{responses: map[string][]FakeResponse{}}
+}
+
+// Expect — Register the response to a command line
+// Responses registered for the same line answer its successive runs, the last one being repeated.
+func (f *FakeExecutor) Expect(line string, response FakeResponse) *FakeExecutor {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.responses[line] = append(f.responses[line], response)
+	return f
+}
+
+// Run — Record the command and answer it with its response
+func (f *FakeExecutor) Run(ctx context.Context, cmd Command, opts *RunOptions) *Result {
+	if opts == nil {
+		opts = &RunOptions{}
+	}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	f.mu.Lock()
+	i := len(f.calls)
+	f.calls = append(f.calls, FakeCall{Command: cmd})
+	queue, ok := f.responses[cmd.Line]
+	response := FakeResponse{ExitCode: 127, Err: fmt.Errorf("unexpected command %q", cmd.Line)}
+	if ok {
+		response = queue[0]
+		if len(queue) > 1 {
+			f.responses[cmd.Line] = queue[1:]
+		}
+	}
+	f.mu.Unlock()
+
+	r := &Result{Command: cmd.Line}
+	if err := ctx.Err(); err != nil {
+		r.ExitCode = -1
+		r.Err = &CommandError{Command: cmd.Line, ExitCode: -1, Err: err}
+		return r
+	}
+
+	// the input is read whole, as a program would read it
+	if opts.Stdin != nil {
+		stdin, _ := io.ReadAll(opts.Stdin)
+		f.mu.Lock()
+		f.calls[i].Stdin = string(stdin)
+		f.mu.Unlock()
+	}
+
+	if response.Err != nil {
+		r.ExitCode = response.ExitCode
+		r.Err = &CommandError{Command: cmd.Line, ExitCode: response.ExitCode, Err: response.Err}
+		return r
+	}
+
+	if response.Delay > 0 {
+		timer, stop := getClock().Timer(response.Delay)
+		defer stop()
+		select {
+		case <-timer:
+		case <-ctx.Done():
+			r.Duration = response.Delay
+			r.ExitCode, r.Signal = 128+int(syscall.SIGTERM), syscall.SIGTERM
+			r.Err = &CommandError{Command: cmd.Line, ExitCode: r.ExitCode, Signal: r.Signal, Err: ctx.Err()}
+			return r
+		}
+	}
+
+	out := newRunOutput(opts)
+	_, err := io.WriteString(out.stdout, response.Stdout)
+	if _, err2 := io.WriteString(out.stderr, response.Stderr); err == nil {
+		err = err2
+	}
+	out.flush()
+	if err == nil {
+		err = out.err()
+	}
+
+	r.Stdout, r.Stderr, r.Combined, r.Omitted = out.outBuf.Bytes(), out.errBuf.Bytes(), out.combined.Bytes(), out.combined.omitted
+	r.Duration, r.ExitCode, r.Signal = response.Delay, response.ExitCode, response.Signal
+	if r.Signal != 0 {
+		r.ExitCode = 128 + int(r.Signal)
+	}
+
+	switch {
+	case r.ExitCode != 0:
+		r.Err = &CommandError{Command: cmd.Line, ExitCode: r.ExitCode, Signal: r.Signal}
+	case err != nil:
+		r.Err = &CommandError{Command: cmd.Line, Err: err}
+	}
+	return r
+}
+
+// Calls — Return the commands received, in order
+func (f *FakeExecutor) Calls() []FakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]FakeCall(nil), f.calls...)
+}
+
+// Verify — Check that the command lines received are lines, in that order
+// if err := fake.Verify("git fetch", "git rev-parse HEAD"); err != nil { t.Error(err) }
+func (f *FakeExecutor) Verify(lines ...string) error {
+	calls := f.Calls()
+	got := make([]string, len(calls))
+	for i, call := range calls {
+		got[i] = call.Line
+	}
+
+	for i := 0; i < len(got) || i < len(lines); i++ {
+		switch {
+		case i == len(got):
+			return fmt.Errorf("command %d: expected %q, got no more commands", i+1, lines[i])
+		case i == len(lines):
+			return fmt.Errorf("command %d: unexpected %q", i+1, got[i])
+		case got[i] != lines[i]:
+			return fmt.Errorf("command %d: expected %q, got %q; commands: %s", i+1, lines[i], got[i], strings.Join(got, ", "))
+		}
+	}
+	return nil
+}
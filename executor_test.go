@@ -0,0 +1,85 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecutor(t *testing.T) {
+	fake := NewFakeExecutor().
+		Expect("git rev-parse HEAD", FakeResponse{Stdout: "1a2b3c\n"}).
+		Expect("make test", FakeResponse{Stdout: "ok\n", Stderr: "warning\n", ExitCode: 2}).
+		Expect("flaky", FakeResponse{ExitCode: 1}).
+		Expect("flaky", FakeResponse{}).
+		Expect("missing", FakeResponse{ExitCode: 127, Err: exec.ErrNotFound})
+	defer SetExecutor(SetExecutor(fake))
+
+	var output []string
+	var retVal int
+	equal(t, "1a2b3c", Exec("git rev-parse HEAD", &output, &retVal))
+	equal(t, 0, retVal)
+	var lines []string
+	r := RunContext(context.Background(), "make test", &RunOptions{OnStderrLine: func(line string) { lines = append(lines, line) }})
+	equal(t, 2, r.ExitCode)
+	equal(t, "ok\n", string(r.Stdout))
+	equal(t, "warning\n", string(r.Stderr))
+	equal(t, []string{"warning"}, lines)
+
+	equal(t, 1, Run("flaky").ExitCode)
+	equal(t, 0, Run("flaky").ExitCode)
+	equal(t, 0, Run("flaky").ExitCode)
+	equal(t, true, errors.Is(Run("missing").Err, exec.ErrNotFound))
+
+	r = Run("rm -rf /")
+	equal(t, 127, r.ExitCode)
+	equal(t, true, strings.Contains(r.Err.Error(), "unexpected command"))
+
+	equal(t, nil, fake.Verify("git rev-parse HEAD", "make test", "flaky", "flaky", "flaky", "missing", "rm -rf /"))
+	unequal(t, nil, fake.Verify("git rev-parse HEAD", "make test"))
+	unequal(t, nil, fake.Verify("make test", "git rev-parse HEAD", "flaky", "flaky", "flaky", "missing", "rm -rf /"))
+	equal(t, []string{"git", "rev-parse", "HEAD"}, fake.Calls()[0].Args)
+
+	// delays follow the package clock and give way to the context
+	fake = NewFakeExecutor().Expect("backup", FakeResponse{Stdout: "done\n", Delay: time.Hour})
+	SetExecutor(fake)
+	clock := NewFakeClock(time.Date(2018, 4, 27, 10, 23, 14, 0, time.UTC))
+	defer SetClock(SetClock(clock))
+	done := make(chan *Result)
+	go func() { done <- Run("backup") }()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour)
+	r = <-done
+	equal(t, "done\n", string(r.Stdout))
+	equal(t, time.Hour, r.Duration)
+	r = RunContext(context.Background(), "backup", &RunOptions{Timeout: 50 * time.Millisecond})
+	equal(t, true, errors.Is(r.Err, context.DeadlineExceeded))
+	equal(t, 128+15, r.ExitCode)
+	equal(t, 0, clock.Waiters())
+
+	// pipelines and shell commands go through the executor too
+	fake = NewFakeExecutor().
+		Expect("ps aux", FakeResponse{Stdout: "nginx\nsshd\n"}).
+		Expect("grep nginx", FakeResponse{Stdout: "nginx\n"}).
+		Expect("ls | wc -l", FakeResponse{Stdout: "3\n"})
+	SetExecutor(fake)
+	p := NewPipeline("ps aux", "grep nginx").Run(context.Background(), nil)
+	equal(t, "nginx\n", string(p.Stdout))
+	equal(t, []int{0, 0}, p.PipeStatus())
+	for _, call := range fake.Calls() {
+		if call.Line == "grep nginx" {
+			equal(t, "nginx\nsshd\n", call.Stdin)
+		}
+	}
+	defer SetShell(SetShell("/bin/sh"))
+	out, err := ShellExec("ls | wc -l")
+	equal(t, nil, err)
+	equal(t, "3\n", out)
+	equal(t, []string{"/bin/sh", "-c", "ls | wc -l"}, fake.Calls()[2].Args)
+
+	equal(t, fake, SetExecutor(nil))
+	equal(t, NewRealExecutor(), getExecutor())
+}
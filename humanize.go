@@ -0,0 +1,383 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Locale — Translations for humanized times and for month and day names
+// Units holds the forms of "year", "month", "week", "day", "hour", "minute" and "second" in the order
+// of the indexes returned by Plural. RelativeUnits, when set, holds the forms used inside Past and
+// Future for languages where they differ. Past and Future are fmt formats for a count with its unit.
+// MonthsGenitive, when set, holds the month names used with a day, as in "27 апреля", while Months
+// holds the standalone ones, as in "апрель 2018".
+type Locale struct {
+	Name           string
+	Months         [12]string
+	MonthsGenitive [12]string
+	MonthsShort    [12]string
+	Days           [7]string // from Sunday
+	DaysShort      [7]string
+	Units          map[string][]string
+	RelativeUnits  map[string][]string
+	Plural         func(n int) int
+	Past           string
+	Future         string
+	Now            string
+}
+
+// LocaleEnglish — English, the default locale
+var LocaleEnglish = &Locale{
+	Name:        "en",
+	Months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	MonthsShort: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	Days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	DaysShort:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+	Units: map[string][]string{
+		"year": {"year", "years"}, "month": {"month", "months"}, "week": {"week", "weeks"}, "day": {"day", "days"},
+		"hour": {"hour", "hours"}, "minute": {"minute", "minutes"}, "second": {"second", "seconds"},
+	},
+	Plural: func(n int) int {
+		if n == 1 {
+			return 0
+		}
+		return 1
+	},
+	Past:   "%s ago",
+	Future: "in %s",
+	Now:    "just now",
+}
+
+// LocaleFrench — French
+var LocaleFrench = &Locale{
+	Name:        "fr",
+	Months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+	MonthsShort: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+	Days:        [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+	DaysShort:   [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	Units: map[string][]string{
+		"year": {"an", "ans"}, "month": {"mois", "mois"}, "week": {"semaine", "semaines"}, "day": {"jour", "jours"},
+		"hour": {"heure", "heures"}, "minute": {"minute", "minutes"}, "second": {"seconde", "secondes"},
+	},
+	// 0 and 1 are singular
+	Plural: func(n int) int {
+		if n <= 1 {
+			return 0
+		}
+		return 1
+	},
+	Past:   "il y a %s",
+	Future: "dans %s",
+	Now:    "à l'instant",
+}
+
+// LocaleRussian — Russian, with the one, few and many plural forms
+var LocaleRussian = &Locale{
+	Name:           "ru",
+	Months:         [12]string{"январь", "февраль", "март", "апрель", "май", "июнь", "июль", "август", "сентябрь", "октябрь", "ноябрь", "декабрь"},
+	MonthsGenitive: [12]string{"января", "февраля", "марта", "апреля", "мая", "июня", "июля", "августа", "сентября", "октября", "ноября", "декабря"},
+	MonthsShort:    [12]string{"янв", "фев", "мар", "апр", "май", "июн", "июл", "авг", "сен", "окт", "ноя", "дек"},
+	Days:           [7]string{"воскресенье", "понедельник", "вторник", "среда", "четверг", "пятница", "суббота"},
+	DaysShort:      [7]string{"вс", "пн", "вт", "ср", "чт", "пт", "сб"},
+	Units: map[string][]string{
+		"year": {"год", "года", "лет"}, "month": {"месяц", "месяца", "месяцев"}, "week": {"неделя", "недели", "недель"},
+		"day": {"день", "дня", "дней"}, "hour": {"час", "часа", "часов"}, "minute": {"минута", "минуты", "минут"},
+		"second": {"секунда", "секунды", "секунд"},
+	},
+	// feminine units take the accusative after "через" and before "назад"
+	RelativeUnits: map[string][]string{
+		"week": {"неделю", "недели", "недель"}, "minute": {"минуту", "минуты", "минут"}, "second": {"секунду", "секунды", "секунд"},
+	},
+	// 1, 21, 31: one; 2-4, 22-24: few; 0, 5-20, 25-30: many
+	Plural: func(n int) int {
+		switch {
+		case n%10 == 1 && n%100 != 11:
+			return 0
+		case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+			return 1
+		}
+		return 2
+	},
+	Past:   "%s назад",
+	Future: "через %s",
+	Now:    "только что",
+}
+
+var (
+	localeMu sync.RWMutex
+	locale   = LocaleEnglish
+	locales  = map[string]*Locale{"en": LocaleEnglish, "fr": LocaleFrench, "ru": LocaleRussian}
+)
+
+// SetLocale — Replace the package default locale and return the previous one
+// HumanizeSince, HumanizeDuration and ParseHumanDuration use the default locale.
+// Passing nil restores English.
+// defer SetLocale(SetLocale(LocaleFrench))
+func SetLocale(l *Locale) *Locale {
+	if l == nil {
+		l = LocaleEnglish
+	}
+
+	localeMu.Lock()
+	defer localeMu.Unlock()
+
+	prev := locale
+	locale = l
+	return prev
+}
+
+// getLocale returns the package default locale
+func getLocale() *Locale {
+	localeMu.RLock()
+	defer localeMu.RUnlock()
+	return locale
+}
+
+// RegisterLocale — Make a locale available to LookupLocale under its Name
+func RegisterLocale(l *Locale) {
+	localeMu.Lock()
+	defer localeMu.Unlock()
+	locales[l.Name] = l
+}
+
+// LookupLocale — Return the locale registered under name, "en", "fr" and "ru" are built in
+func LookupLocale(name string) (*Locale, bool) {
+	localeMu.RLock()
+	defer localeMu.RUnlock()
+	l, ok := locales[name]
+	return l, ok
+}
+
+// unit returns a count with the plural form of unit
+func (l *Locale) unit(n int, unit string, relative bool) string {
+	forms := l.Units[unit]
+	if relative && l.RelativeUnits[unit] != nil {
+		forms = l.RelativeUnits[unit]
+	}
+
+	i := l.Plural(n)
+	if i >= len(forms) {
+		i = len(forms) - 1
+	}
+	return strconv.Itoa(n) + " " + forms[i]
+}
+
+// Since — Describe t relative to now in the largest calendar unit, see HumanizeSince
+func (l *Locale) Since(t time.Time) string {
+	now := getClock().Now()
+	i := DateDiff(now, t.In(now.Location()), false)
+
+	var n int
+	var unit string
+	switch {
+	case i.Years > 0:
+		n, unit = i.Years, "year"
+	case i.Months > 0:
+		n, unit = i.Months, "month"
+	case i.Days >= 7:
+		n, unit = i.Days/7, "week"
+	case i.Days > 0:
+		n, unit = i.Days, "day"
+	case i.Hours > 0:
+		n, unit = i.Hours, "hour"
+	case i.Minutes > 0:
+		n, unit = i.Minutes, "minute"
+	case i.Seconds > 0:
+		n, unit = i.Seconds, "second"
+	default:
+		return l.Now
+	}
+
+	if i.Invert {
+		return fmt.Sprintf(l.Past, l.unit(n, unit, true))
+	}
+	return fmt.Sprintf(l.Future, l.unit(n, unit, true))
+}
+
+// Duration — Describe d in days, hours, minutes and seconds, see HumanizeDuration
+func (l *Locale) Duration(d time.Duration, precision int) string {
+	if d < 0 {
+		d = -d
+	}
+
+	units := []struct {
+		name string
+		size time.Duration
+	}{{"day", 24 * time.Hour}, {"hour", time.Hour}, {"minute", time.Minute}, {"second", time.Second}}
+
+	var parts []string
+	for _, u := range units {
+		if n := int(d / u.size); n > 0 {
+			parts = append(parts, l.unit(n, u.name, false))
+			d -= time.Duration(n) * u.size
+			if len(parts) == precision {
+				break
+			}
+		}
+	}
+
+	if len(parts) == 0 {
+		return l.unit(0, "second", false)
+	}
+	return strings.Join(parts, " ")
+}
+
+// DateFormat — Format t like DateFormat, with the month and day names of the locale
+// The English ordinal suffix of S is left out in other locales. F is the genitive month name when
+// format also has the day of the month, d or j, and the locale has MonthsGenitive.
+// LocaleFrench.DateFormat(t, "l j F Y") == "vendredi 27 avril 2018"
+// LocaleRussian.DateFormat(t, "j F Y") == "27 апреля 2018"
+func (l *Locale) DateFormat(t time.Time, format string) string {
+	months := l.Months
+	if l.MonthsGenitive[0] != "" && formatHasDay(format) {
+		months = l.MonthsGenitive
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c == '\\' && i+1 < len(format) {
+			i++
+			b.WriteByte(format[i])
+			continue
+		}
+
+		switch c {
+		case 'D':
+			b.WriteString(l.DaysShort[t.Weekday()])
+		case 'l':
+			b.WriteString(l.Days[t.Weekday()])
+		case 'M':
+			b.WriteString(l.MonthsShort[t.Month()-1])
+		case 'F':
+			b.WriteString(months[t.Month()-1])
+		case 'S':
+			if l.Name == LocaleEnglish.Name {
+				b.WriteString(daySuffix(t.Day()))
+			}
+		default:
+			if char, ok := dateChars[c]; ok {
+				b.WriteString(char.format(t))
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// formatHasDay reports whether a date format shows the day of the month
+func formatHasDay(format string) bool {
+	for i := 0; i < len(format); i++ {
+		switch format[i] {
+		case '\\':
+			i++
+		case 'd', 'j':
+			return true
+		}
+	}
+	return false
+}
+
+// Date — Format a Unix timestamp like PhpDate, with the month and day names of the locale
+func (l *Locale) Date(format string, timestamp int64) string {
+	return l.DateFormat(time.Unix(timestamp, 0).In(getLocation()), format)
+}
+
+// HumanizeSince — Describe t relative to the current time in the default locale
+// The largest calendar unit between them is used, counted like DateDiff.
+// HumanizeSince(threeHoursAgo) == "3 hours ago"
+// HumanizeSince(inTwoDays) == "in 2 days"
+func HumanizeSince(t time.Time) string {
+	return getLocale().Since(t)
+}
+
+// HumanizeDuration — Describe d in days, hours, minutes and seconds in the default locale
+// At most precision units are shown, largest first, all of them when precision is 0 or less.
+// HumanizeDuration(64*time.Minute, 2) == "1 hour 4 minutes"
+// HumanizeDuration(90061*time.Second, 2) == "1 day 1 hour"
+func HumanizeDuration(d time.Duration, precision int) string {
+	return getLocale().Duration(d, precision)
+}
+
+var (
+	humanDurationRe    = regexp.MustCompile(`^(\d+(?:\.\d+)?|\.\d+)\s*([^\s\d,.]+)\.?`)
+	humanDurationSepRe = regexp.MustCompile(`^(?:\s|,|and\b)*`)
+
+	humanDurationUnits = map[string]time.Duration{
+		"ns": time.Nanosecond, "nanosecond": time.Nanosecond, "nanoseconds": time.Nanosecond,
+		"us": time.Microsecond, "µs": time.Microsecond, "μs": time.Microsecond, "microsecond": time.Microsecond, "microseconds": time.Microsecond,
+		"ms": time.Millisecond, "millisecond": time.Millisecond, "milliseconds": time.Millisecond,
+		"s": time.Second, "sec": time.Second, "secs": time.Second, "second": time.Second, "seconds": time.Second,
+		"m": time.Minute, "min": time.Minute, "mins": time.Minute, "minute": time.Minute, "minutes": time.Minute,
+		"h": time.Hour, "hr": time.Hour, "hrs": time.Hour, "hour": time.Hour, "hours": time.Hour,
+		"d": 24 * time.Hour, "day": 24 * time.Hour, "days": 24 * time.Hour,
+		"w": 7 * 24 * time.Hour, "wk": 7 * 24 * time.Hour, "wks": 7 * 24 * time.Hour, "week": 7 * 24 * time.Hour, "weeks": 7 * 24 * time.Hour,
+	}
+	localeUnitSizes = map[string]time.Duration{
+		"week": 7 * 24 * time.Hour, "day": 24 * time.Hour, "hour": time.Hour, "minute": time.Minute, "second": time.Second,
+	}
+)
+
+// ParseHumanDuration — Parse a duration such as "1h30m", "2 days 3 hours" or "1 day, 2 hours and 5 minutes"
+// Units are English or those of the default locale; a day is 24 hours and a week 7 days. Months and
+// years have no fixed length and are refused. A leading - makes the duration negative.
+// ParseHumanDuration("1.5 hours") == 90 * time.Minute
+func ParseHumanDuration(str string) (time.Duration, error) {
+	s := strings.ToLower(strings.TrimSpace(str))
+	sign := 1.0
+	if strings.HasPrefix(s, "-") {
+		sign, s = -1, strings.TrimSpace(s[1:])
+	}
+	if s == "" {
+		return 0, fmt.Errorf("invalid duration %q", str)
+	}
+
+	l := getLocale()
+	var total float64
+	for s != "" {
+		m := humanDurationRe.FindStringSubmatch(s)
+		if m == nil {
+			return 0, fmt.Errorf("invalid duration %q", str)
+		}
+
+		size, ok := humanDurationUnits[m[2]]
+		if !ok {
+			size, ok = l.unitSize(m[2])
+		}
+		if !ok {
+			return 0, fmt.Errorf("unknown unit %q in duration %q", m[2], str)
+		}
+
+		n, _ := strconv.ParseFloat(m[1], 64)
+		total += n * float64(size)
+		s = s[len(m[0]):]
+		s = s[len(humanDurationSepRe.FindString(s)):]
+	}
+
+	if total > math.MaxInt64 {
+		return 0, fmt.Errorf("duration %q is too long", str)
+	}
+	return time.Duration(sign * total), nil
+}
+
+// unitSize returns the length of a unit named in the locale
+func (l *Locale) unitSize(name string) (time.Duration, bool) {
+	for _, units := range []map[string][]string{l.Units, l.RelativeUnits} {
+		for unit, forms := range units {
+			for _, form := range forms {
+				if strings.ToLower(form) == name {
+					size, ok := localeUnitSizes[unit]
+					return size, ok
+				}
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,88 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHumanize(t *testing.T) {
+	now := time.Date(2018, 4, 27, 10, 23, 14, 0, time.UTC)
+	defer SetClock(SetClock(NewFakeClock(now)))
+
+	equal(t, "just now", HumanizeSince(now))
+	equal(t, "1 second ago", HumanizeSince(now.Add(-time.Second)))
+	equal(t, "3 hours ago", HumanizeSince(now.Add(-3*time.Hour-20*time.Minute)))
+	equal(t, "in 2 days", HumanizeSince(now.Add(50*time.Hour)))
+	equal(t, "2 weeks ago", HumanizeSince(now.AddDate(0, 0, -15)))
+	equal(t, "1 month ago", HumanizeSince(time.Date(2018, 3, 27, 10, 23, 14, 0, time.UTC)))
+	equal(t, "in 1 year", HumanizeSince(time.Date(2019, 5, 1, 0, 0, 0, 0, time.UTC)))
+
+	equal(t, "1 hour 4 minutes", HumanizeDuration(64*time.Minute+30*time.Second, 2))
+	equal(t, "1 hour 4 minutes 30 seconds", HumanizeDuration(64*time.Minute+30*time.Second, 0))
+	equal(t, "1 day 1 hour", HumanizeDuration(-90061*time.Second, 2))
+	equal(t, "2 days 1 second", HumanizeDuration(48*time.Hour+time.Second, 2))
+	equal(t, "0 seconds", HumanizeDuration(500*time.Millisecond, 1))
+
+	for str, expected := range map[string]time.Duration{
+		"1h30m":                        90 * time.Minute,
+		"1.5 hours":                    90 * time.Minute,
+		"2 days 3 hours":               51 * time.Hour,
+		"1 day, 2 hours and 5 minutes": 26*time.Hour + 5*time.Minute,
+		"-2w":                          -14 * 24 * time.Hour,
+		"90 Sec.":                      90 * time.Second,
+		"250ms":                        250 * time.Millisecond,
+	} {
+		d, err := ParseHumanDuration(str)
+		equal(t, nil, err)
+		equal(t, expected, d)
+	}
+	for _, str := range []string{"", "3", "2 months", "5 parsecs", "1h junk"} {
+		_, err := ParseHumanDuration(str)
+		unequal(t, nil, err)
+	}
+
+	// plural rules with more than two forms
+	ru := LocaleRussian
+	equal(t, "1 минута", ru.Duration(time.Minute, 1))
+	equal(t, "3 минуты", ru.Duration(3*time.Minute, 1))
+	equal(t, "11 минут", ru.Duration(11*time.Minute, 1))
+	equal(t, "21 минута", ru.Duration(21*time.Minute, 1))
+	equal(t, "22 часа 5 минут", ru.Duration(22*time.Hour+5*time.Minute, 0))
+	equal(t, "1 минуту назад", ru.Since(now.Add(-time.Minute)))
+	equal(t, "через 5 дней", ru.Since(now.AddDate(0, 0, 5)))
+	equal(t, "0 секунд", ru.Duration(0, 1))
+
+	fr := LocaleFrench
+	equal(t, "il y a 1 heure", fr.Since(now.Add(-time.Hour)))
+	equal(t, "dans 3 mois", fr.Since(now.AddDate(0, 3, 0)))
+	equal(t, "0 seconde", fr.Duration(0, 1))
+	equal(t, "à l'instant", fr.Since(now))
+
+	defer SetLocale(SetLocale(LocaleFrench))
+	equal(t, "il y a 2 jours", HumanizeSince(now.AddDate(0, 0, -2)))
+	d, err := ParseHumanDuration("2 heures 30 minutes")
+	equal(t, nil, err)
+	equal(t, 150*time.Minute, d)
+	equal(t, LocaleFrench, SetLocale(nil))
+	equal(t, LocaleEnglish, getLocale())
+
+	// localized names
+	equal(t, "vendredi 27 avril 2018", fr.DateFormat(now, "l j F Y"))
+	equal(t, "пт, 27 апр 2018", ru.DateFormat(now, "D, j M Y"))
+	equal(t, "27 апреля 2018", ru.DateFormat(now, "j F Y"))
+	equal(t, "апрель 2018", ru.DateFormat(now, "F Y"))
+	equal(t, "апрель d", ru.DateFormat(now, `F \d`))
+	equal(t, "Friday 27th of April", LocaleEnglish.DateFormat(now, "l jS \\of F"))
+	equal(t, DateFormat(now, "D, d M Y H:i:s"), LocaleEnglish.DateFormat(now, "D, d M Y H:i:s"))
+	defer SetLocation(SetLocation(time.UTC))
+	equal(t, "ven. 27 avr. 2018", fr.Date("D j M Y", now.Unix()))
+
+	l, ok := LookupLocale("ru")
+	equal(t, true, ok)
+	equal(t, LocaleRussian, l)
+	_, ok = LookupLocale("xx")
+	equal(t, false, ok)
+	RegisterLocale(&Locale{Name: "xx"})
+	_, ok = LookupLocale("xx")
+	equal(t, true, ok)
+}
@@ -0,0 +1,430 @@
+package utils
+
+import (
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Interval — An amount of time, like PHP's DateInterval
+// TotalDays is the exact number of days between the two dates of DateDiff, and -1 for other intervals.
+// Invert makes DateAdd subtract the interval instead of adding it.
+type Interval struct {
+	Years, Months, Days     int
+	Hours, Minutes, Seconds int
+	Microseconds            int
+	Invert                  bool
+	TotalDays               int
+}
+
+// NewInterval — Create an interval from an ISO 8601 duration such as "P1Y2M10DT2H30M"
+// Weeks ("P2W") are added to the days, seconds may have a fraction ("PT1.5S"),
+// and a leading "-" sets Invert.
+func NewInterval(spec string) (*Interval, error) {
+	i := &Interval{TotalDays: -1}
+
+	str := spec
+	if strings.HasPrefix(str, "-") {
+		i.Invert, str = true, str[1:]
+	}
+	if !strings.HasPrefix(str, "P") || len(str) < 2 || str == "PT" || strings.HasSuffix(str, "T") {
+		return nil, fmt.Errorf("unknown or bad format (%s)", spec)
+	}
+
+	timePart := false
+	units := "YMWD"
+	for str = str[1:]; str != ""; {
+		if str[0] == 'T' {
+			if timePart {
+				return nil, fmt.Errorf("unknown or bad format (%s)", spec)
+			}
+			timePart, units, str = true, "HMS", str[1:]
+			continue
+		}
+
+		n := 0
+		for n < len(str) && (str[n] >= '0' && str[n] <= '9' || str[n] == '.' && timePart) {
+			n++
+		}
+		if n == 0 || n == len(str) {
+			return nil, fmt.Errorf("unknown or bad format (%s)", spec)
+		}
+
+		// units must come in order and only once
+		unit := strings.IndexByte(units, str[n])
+		if unit == -1 {
+			return nil, fmt.Errorf("unknown or bad format (%s)", spec)
+		}
+		designator := units[unit]
+		units = units[unit+1:]
+
+		number := str[:n]
+		str = str[n+1:]
+		if strings.Contains(number, ".") {
+			if designator != 'S' {
+				return nil, fmt.Errorf("unknown or bad format (%s)", spec)
+			}
+			f, err := strconv.ParseFloat(number, 64)
+			if err != nil {
+				return nil, fmt.Errorf("unknown or bad format (%s)", spec)
+			}
+			i.Seconds = int(f)
+			i.Microseconds = int((f-float64(i.Seconds))*1e6 + 0.5)
+			continue
+		}
+
+		v, err := strconv.Atoi(number)
+		if err != nil {
+			return nil, fmt.Errorf("unknown or bad format (%s)", spec)
+		}
+		switch {
+		case designator == 'Y':
+			i.Years = v
+		case designator == 'M' && !timePart:
+			i.Months = v
+		case designator == 'W':
+			i.Days += v * 7
+		case designator == 'D':
+			i.Days += v
+		case designator == 'H':
+			i.Hours = v
+		case designator == 'M':
+			i.Minutes = v
+		case designator == 'S':
+			i.Seconds = v
+		}
+	}
+
+	return i, nil
+}
+
+// String — Return the ISO 8601 duration of the interval, "PT0S" when it is empty
+func (i *Interval) String() string {
+	var b strings.Builder
+	if i.Invert {
+		b.WriteByte('-')
+	}
+	b.WriteByte('P')
+
+	for _, part := range []struct {
+		v    int
+		unit byte
+	}{{i.Years, 'Y'}, {i.Months, 'M'}, {i.Days, 'D'}} {
+		if part.v != 0 {
+			b.WriteString(strconv.Itoa(part.v))
+			b.WriteByte(part.unit)
+		}
+	}
+
+	if i.Hours != 0 || i.Minutes != 0 || i.Seconds != 0 || i.Microseconds != 0 {
+		b.WriteByte('T')
+		if i.Hours != 0 {
+			b.WriteString(strconv.Itoa(i.Hours) + "H")
+		}
+		if i.Minutes != 0 {
+			b.WriteString(strconv.Itoa(i.Minutes) + "M")
+		}
+		if i.Microseconds != 0 {
+			s := strings.TrimRight(fmt.Sprintf("%d.%06d", i.Seconds, i.Microseconds), "0")
+			b.WriteString(s + "S")
+		} else if i.Seconds != 0 {
+			b.WriteString(strconv.Itoa(i.Seconds) + "S")
+		}
+	}
+
+	if b.Len() == 1 || b.Len() == 2 && i.Invert {
+		b.WriteString("T0S")
+	}
+	return b.String()
+}
+
+// Format — Format the interval like PHP's DateInterval::format
+// %Y %M %D %H %I %S are at least two digits, %y %m %d %h %i %s are not,
+// %F and %f are the microseconds with and without padding, %a is TotalDays or "(unknown)",
+// %R is "+" or "-", %r is "-" or empty, and %% is a literal %.
+// Format("%a days, %h hours") == "41 days, 2 hours"
+func (i *Interval) Format(format string) string {
+	var b strings.Builder
+	for n := 0; n < len(format); n++ {
+		if format[n] != '%' || n+1 == len(format) {
+			b.WriteByte(format[n])
+			continue
+		}
+
+		n++
+		switch format[n] {
+		case 'Y':
+			fmt.Fprintf(&b, "%02d", i.Years)
+		case 'y':
+			b.WriteString(strconv.Itoa(i.Years))
+		case 'M':
+			fmt.Fprintf(&b, "%02d", i.Months)
+		case 'm':
+			b.WriteString(strconv.Itoa(i.Months))
+		case 'D':
+			fmt.Fprintf(&b, "%02d", i.Days)
+		case 'd':
+			b.WriteString(strconv.Itoa(i.Days))
+		case 'H':
+			fmt.Fprintf(&b, "%02d", i.Hours)
+		case 'h':
+			b.WriteString(strconv.Itoa(i.Hours))
+		case 'I':
+			fmt.Fprintf(&b, "%02d", i.Minutes)
+		case 'i':
+			b.WriteString(strconv.Itoa(i.Minutes))
+		case 'S':
+			fmt.Fprintf(&b, "%02d", i.Seconds)
+		case 's':
+			b.WriteString(strconv.Itoa(i.Seconds))
+		case 'F':
+			fmt.Fprintf(&b, "%06d", i.Microseconds)
+		case 'f':
+			b.WriteString(strconv.Itoa(i.Microseconds))
+		case 'a':
+			if i.TotalDays < 0 {
+				b.WriteString("(unknown)")
+			} else {
+				b.WriteString(strconv.Itoa(i.TotalDays))
+			}
+		case 'R':
+			if i.Invert {
+				b.WriteByte('-')
+			} else {
+				b.WriteByte('+')
+			}
+		case 'r':
+			if i.Invert {
+				b.WriteByte('-')
+			}
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(format[n])
+		}
+	}
+
+	return b.String()
+}
+
+// scale returns the interval multiplied by k
+func (i *Interval) scale(k int) *Interval {
+	return &Interval{
+		Years: i.Years * k, Months: i.Months * k, Days: i.Days * k,
+		Hours: i.Hours * k, Minutes: i.Minutes * k, Seconds: i.Seconds * k,
+		Microseconds: i.Microseconds * k,
+		Invert:       i.Invert,
+		TotalDays:    -1,
+	}
+}
+
+// empty reports whether the interval does not move a time
+func (i *Interval) empty() bool {
+	return i.Years == 0 && i.Months == 0 && i.Days == 0 &&
+		i.Hours == 0 && i.Minutes == 0 && i.Seconds == 0 && i.Microseconds == 0
+}
+
+// civilDay returns the number of days since the Unix epoch of the date of t
+func civilDay(t time.Time) int64 {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400
+}
+
+// wallClock returns the time of day shown on the clock of t
+func wallClock(t time.Time) time.Duration {
+	hour, min, sec := t.Clock()
+	return time.Duration(hour)*time.Hour + time.Duration(min)*time.Minute +
+		time.Duration(sec)*time.Second + time.Duration(t.Nanosecond()/1000)*time.Microsecond
+}
+
+// DateDiff — Return the difference between two times
+// The interval goes from origin to target; Invert is set when target is before origin,
+// unless absolute is true. Both times are compared on their wall clocks when they share
+// the same location, and in UTC otherwise.
+// DateDiff(jan31, mar1, false).Format("%m month %d day") == "1 month 1 day"
+func DateDiff(origin, target time.Time, absolute bool) *Interval {
+	a, b := origin, target
+	invert := b.Before(a)
+	if invert {
+		a, b = b, a
+	}
+	if a.Location() != b.Location() {
+		a, b = a.UTC(), b.UTC()
+	}
+
+	// whole months first, as DateAdd adds them, then the days and the time left
+	months := (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+	anchor := addInterval(a, &Interval{Months: months}, 1)
+	if wallClock(anchor) > wallClock(b) && civilDay(anchor) >= civilDay(b) || civilDay(anchor) > civilDay(b) {
+		months--
+		anchor = addInterval(a, &Interval{Months: months}, 1)
+	}
+
+	days := int(civilDay(b) - civilDay(anchor))
+	clock := wallClock(b) - wallClock(anchor)
+	if clock < 0 {
+		days, clock = days-1, clock+24*time.Hour
+	}
+
+	i := &Interval{
+		Years:        months / 12,
+		Months:       months % 12,
+		Days:         days,
+		Hours:        int(clock / time.Hour),
+		Minutes:      int(clock % time.Hour / time.Minute),
+		Seconds:      int(clock % time.Minute / time.Second),
+		Microseconds: int(clock % time.Second / time.Microsecond),
+		Invert:       invert && !absolute,
+	}
+
+	i.TotalDays = int(civilDay(b) - civilDay(a))
+	if wallClock(b) < wallClock(a) {
+		i.TotalDays--
+	}
+
+	return i
+}
+
+// addInterval adds sign times the interval to t
+func addInterval(t time.Time, i *Interval, sign int) time.Time {
+	if i.Invert {
+		sign = -sign
+	}
+
+	y, m, d := t.Date()
+	hour, min, sec := t.Clock()
+
+	// years and months keep the day, but stay within the target month
+	month := time.Date(y+sign*i.Years, m+time.Month(sign*i.Months), 1, 0, 0, 0, 0, time.UTC)
+	if last := daysInMonth(month.Year(), month.Month()); d > last {
+		d = last
+	}
+
+	r := time.Date(month.Year(), month.Month(), d+sign*i.Days, hour, min, sec, t.Nanosecond(), t.Location())
+
+	// hours, minutes and seconds are elapsed time, so they are not affected by DST changes
+	elapsed := time.Duration(i.Hours)*time.Hour + time.Duration(i.Minutes)*time.Minute +
+		time.Duration(i.Seconds)*time.Second + time.Duration(i.Microseconds)*time.Microsecond
+	return r.Add(time.Duration(sign) * elapsed)
+}
+
+// DateAdd — Add an interval to a time
+// Unlike PHP, adding months never overflows into the next month:
+// 2018-01-31 plus P1M is 2018-02-28, not 2018-03-03.
+func DateAdd(t time.Time, i *Interval) time.Time {
+	return addInterval(t, i, 1)
+}
+
+// DateSub — Subtract an interval from a time
+// 2018-03-31 minus P1M is 2018-02-28.
+func DateSub(t time.Time, i *Interval) time.Time {
+	return addInterval(t, i, -1)
+}
+
+// Period — A set of dates recurring at regular intervals, like PHP's DatePeriod
+// The dates run from Start either while they are before End, or for Recurrences repetitions
+// after Start when End is zero.
+type Period struct {
+	Start        time.Time
+	Interval     *Interval
+	End          time.Time
+	Recurrences  int
+	ExcludeStart bool
+	IncludeEnd   bool
+}
+
+// NewPeriod — Create a period of the dates from start up to end, end excluded
+// Usage:
+// for _, t := range NewPeriod(start, week, end).All() { ... }
+func NewPeriod(start time.Time, interval *Interval, end time.Time) *Period {
+	if interval.empty() {
+		panic("interval: cannot be empty")
+	}
+
+	return &Period{Start: start, Interval: interval, End: end}
+}
+
+// NewPeriodRecurrences — Create a period of start and the recurrences dates following it
+func NewPeriodRecurrences(start time.Time, interval *Interval, recurrences int) *Period {
+	if recurrences < 1 {
+		panic("recurrences: must be greater than 0")
+	}
+	if interval.empty() {
+		panic("interval: cannot be empty")
+	}
+
+	return &Period{Start: start, Interval: interval, Recurrences: recurrences}
+}
+
+// ParsePeriod — Create a period from an ISO 8601 repeating interval
+// Either "R4/2012-07-01T00:00:00Z/P7D" or "2012-07-01T00:00:00Z/P7D/2012-08-01T00:00:00Z".
+func ParsePeriod(iso string) (*Period, error) {
+	parts := strings.Split(iso, "/")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("unknown or bad format (%s)", iso)
+	}
+
+	if strings.HasPrefix(parts[0], "R") {
+		recurrences, err := strconv.Atoi(parts[0][1:])
+		if err != nil || recurrences < 1 {
+			return nil, fmt.Errorf("unknown or bad format (%s)", iso)
+		}
+		start, err := time.Parse(time.RFC3339Nano, parts[1])
+		if err != nil {
+			return nil, err
+		}
+		interval, err := NewInterval(parts[2])
+		if err != nil {
+			return nil, err
+		}
+		return NewPeriodRecurrences(start, interval, recurrences), nil
+	}
+
+	start, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, err
+	}
+	interval, err := NewInterval(parts[1])
+	if err != nil {
+		return nil, err
+	}
+	end, err := time.Parse(time.RFC3339Nano, parts[2])
+	if err != nil {
+		return nil, err
+	}
+	return NewPeriod(start, interval, end), nil
+}
+
+// All — Iterate over the dates of the period
+// Every date is computed from Start, so a monthly period starting on the 31st
+// yields the last day of shorter months without drifting.
+func (p *Period) All() iter.Seq2[int, time.Time] {
+	return func(yield func(int, time.Time) bool) {
+		n := 0
+		for k := 0; ; k++ {
+			t := addInterval(p.Start, p.Interval.scale(k), 1)
+
+			if p.End.IsZero() {
+				if k > p.Recurrences {
+					return
+				}
+			} else if t.After(p.End) || t.Equal(p.End) && !p.IncludeEnd {
+				return
+			} else if k > 0 && !t.After(p.Start) {
+				// an inverted interval never reaches End
+				return
+			}
+
+			if k == 0 && p.ExcludeStart {
+				continue
+			}
+			if !yield(n, t) {
+				return
+			}
+			n++
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestInterval(t *testing.T) {
+	specs := map[string]Interval{
+		"P1Y2M10DT2H30M": {Years: 1, Months: 2, Days: 10, Hours: 2, Minutes: 30, TotalDays: -1},
+		"P2W":            {Days: 14, TotalDays: -1},
+		"P1W3D":          {Days: 10, TotalDays: -1},
+		"PT36H":          {Hours: 36, TotalDays: -1},
+		"PT1.5S":         {Seconds: 1, Microseconds: 500000, TotalDays: -1},
+		"P1M":            {Months: 1, TotalDays: -1},
+		"PT1M":           {Minutes: 1, TotalDays: -1},
+		"-P1D":           {Days: 1, Invert: true, TotalDays: -1},
+		"P0D":            {TotalDays: -1},
+	}
+	for spec, expected := range specs {
+		i, err := NewInterval(spec)
+		equal(t, nil, err)
+		equal(t, expected, *i)
+	}
+
+	for _, spec := range []string{"", "P", "PT", "1D", "P1DT", "P1H", "PT1D", "P1D2Y", "P1Y1Y", "P1.5D", "PXD", "P1DT2H3", "P-1D"} {
+		_, err := NewInterval(spec)
+		unequal(t, nil, err)
+	}
+
+	for _, spec := range []string{"P1Y2M10DT2H30M", "P14D", "PT36H", "PT1.5S", "-P1D", "PT0S", "P1YT1S"} {
+		i, _ := NewInterval(spec)
+		equal(t, spec, i.String())
+	}
+	equal(t, "PT0S", (&Interval{}).String())
+
+	i, _ := NewInterval("P1Y2M3DT4H5M6.000007S")
+	equal(t, "01 1 02 2 03 3 04 4 05 5 06 6 000007 7 (unknown) + % %q", i.Format("%Y %y %M %m %D %d %H %h %I %i %S %s %F %f %a %R%r %% %q"))
+	i.Invert = true
+	equal(t, "- -", i.Format("%R %r"))
+}
+
+func TestDateDiff(t *testing.T) {
+	utc := func(y, m, d, h, i, s int) time.Time {
+		return time.Date(y, time.Month(m), d, h, i, s, 0, time.UTC)
+	}
+
+	diffs := []struct {
+		origin, target time.Time
+		format         string
+	}{
+		{utc(2018, 1, 31, 0, 0, 0), utc(2018, 3, 1, 0, 0, 0), "+0y 1m 1d 0h 0i 0s 29a"},
+		{utc(2018, 2, 28, 0, 0, 0), utc(2018, 3, 1, 0, 0, 0), "+0y 0m 1d 0h 0i 0s 1a"},
+		{utc(2000, 2, 29, 0, 0, 0), utc(2018, 4, 27, 10, 23, 14), "+18y 1m 29d 10h 23i 14s 6632a"},
+		{utc(2018, 4, 27, 10, 23, 14), utc(2000, 2, 29, 0, 0, 0), "-18y 1m 29d 10h 23i 14s 6632a"},
+		{utc(2018, 1, 1, 23, 0, 0), utc(2018, 1, 2, 1, 0, 0), "+0y 0m 0d 2h 0i 0s 0a"},
+		{utc(2017, 12, 31, 23, 59, 59), utc(2018, 1, 1, 0, 0, 0), "+0y 0m 0d 0h 0i 1s 0a"},
+		{utc(2018, 1, 1, 0, 0, 0), utc(2018, 1, 1, 0, 0, 0), "+0y 0m 0d 0h 0i 0s 0a"},
+		{utc(2016, 1, 6, 0, 0, 0), utc(2016, 2, 16, 2, 0, 0), "+0y 1m 10d 2h 0i 0s 41a"},
+	}
+	for _, c := range diffs {
+		equal(t, c.format, DateDiff(c.origin, c.target, false).Format("%R%yy %mm %dd %hh %ii %ss %aa"))
+	}
+	equal(t, false, DateDiff(utc(2018, 2, 1, 0, 0, 0), utc(2018, 1, 1, 0, 0, 0), true).Invert)
+
+	// different locations are compared in UTC
+	jakarta := time.FixedZone("WIB", 7*3600)
+	equal(t, "+0d 1h", DateDiff(time.Date(2018, 1, 2, 6, 0, 0, 0, jakarta), utc(2018, 1, 2, 0, 0, 0), false).Format("%R%dd %hh"))
+
+	// the same location is compared on the wall clock
+	amsterdam, _ := time.LoadLocation("Europe/Amsterdam")
+	equal(t, "+1d 0h 1a", DateDiff(time.Date(2021, 3, 27, 12, 0, 0, 0, amsterdam), time.Date(2021, 3, 28, 12, 0, 0, 0, amsterdam), false).Format("%R%dd %hh %aa"))
+}
+
+func TestDateAdd(t *testing.T) {
+	day := func(y, m, d int) time.Time {
+		return time.Date(y, time.Month(m), d, 10, 0, 0, 0, time.UTC)
+	}
+	interval := func(spec string) *Interval {
+		i, err := NewInterval(spec)
+		equal(t, nil, err)
+		return i
+	}
+
+	equal(t, day(2018, 2, 28), DateAdd(day(2018, 1, 31), interval("P1M")))
+	equal(t, day(2020, 2, 29), DateAdd(day(2020, 1, 31), interval("P1M")))
+	equal(t, day(2018, 3, 31), DateAdd(day(2018, 1, 31), interval("P2M")))
+	equal(t, day(2021, 2, 28), DateAdd(day(2020, 2, 29), interval("P1Y")))
+	equal(t, day(2018, 3, 1), DateAdd(day(2018, 1, 31), interval("P1M1D")))
+	equal(t, day(2019, 3, 15), DateAdd(day(2018, 1, 1), interval("P1Y2M14D")))
+	equal(t, day(2018, 1, 2).Add(90*time.Minute), DateAdd(day(2018, 1, 1), interval("P1DT1H30M")))
+	equal(t, day(2018, 2, 28), DateSub(day(2018, 3, 31), interval("P1M")))
+	equal(t, day(2017, 12, 31), DateSub(day(2018, 1, 1), interval("P1D")))
+	equal(t, day(2017, 12, 31), DateAdd(day(2018, 1, 1), interval("-P1D")))
+	equal(t, day(2018, 1, 2), DateSub(day(2018, 1, 1), interval("-P1D")))
+
+	// DateAdd reverts DateDiff when no month end is involved
+	a, b := day(2016, 1, 6), day(2018, 4, 27).Add(83*time.Minute)
+	equal(t, b, DateAdd(a, DateDiff(a, b, false)))
+	equal(t, a, DateAdd(b, DateDiff(b, a, false)))
+
+	amsterdam, _ := time.LoadLocation("Europe/Amsterdam")
+	dst := time.Date(2021, 3, 27, 12, 0, 0, 0, amsterdam)
+	equal(t, "2021-03-28 12:00:00 +0200", DateAdd(dst, interval("P1D")).Format("2006-01-02 15:04:05 -0700"))
+	equal(t, "2021-03-28 13:00:00 +0200", DateAdd(dst, interval("PT24H")).Format("2006-01-02 15:04:05 -0700"))
+}
+
+func TestPeriod(t *testing.T) {
+	dates := func(p *Period) []string {
+		var s []string
+		for n, d := range p.All() {
+			equal(t, len(s), n)
+			s = append(s, d.Format("2006-01-02"))
+		}
+		return s
+	}
+
+	start := time.Date(2018, 1, 2, 0, 0, 0, 0, time.UTC) // Tuesday
+	end := time.Date(2018, 2, 13, 0, 0, 0, 0, time.UTC)
+	twoWeeks, _ := NewInterval("P2W")
+
+	p := NewPeriod(start, twoWeeks, end)
+	equal(t, []string{"2018-01-02", "2018-01-16", "2018-01-30"}, dates(p))
+	p.IncludeEnd = true
+	equal(t, []string{"2018-01-02", "2018-01-16", "2018-01-30", "2018-02-13"}, dates(p))
+	p.ExcludeStart = true
+	equal(t, []string{"2018-01-16", "2018-01-30", "2018-02-13"}, dates(p))
+
+	month, _ := NewInterval("P1M")
+	p = NewPeriodRecurrences(time.Date(2018, 1, 31, 0, 0, 0, 0, time.UTC), month, 4)
+	equal(t, []string{"2018-01-31", "2018-02-28", "2018-03-31", "2018-04-30", "2018-05-31"}, dates(p))
+	p.ExcludeStart = true
+	equal(t, []string{"2018-02-28", "2018-03-31", "2018-04-30", "2018-05-31"}, dates(p))
+
+	for _, d := range p.All() {
+		equal(t, "2018-02-28", d.Format("2006-01-02"))
+		break
+	}
+
+	back, _ := NewInterval("-P1D")
+	equal(t, []string{"2018-01-02"}, dates(NewPeriod(start, back, end)))
+
+	p, err := ParsePeriod("R4/2012-07-01T00:00:00Z/P7D")
+	equal(t, nil, err)
+	equal(t, []string{"2012-07-01", "2012-07-08", "2012-07-15", "2012-07-22", "2012-07-29"}, dates(p))
+	p, err = ParsePeriod("2012-07-01T00:00:00Z/P10D/2012-07-31T00:00:00Z")
+	equal(t, nil, err)
+	equal(t, []string{"2012-07-01", "2012-07-11", "2012-07-21"}, dates(p))
+
+	for _, iso := range []string{"R4/2012-07-01T00:00:00Z", "R0/2012-07-01T00:00:00Z/P7D", "Rx/2012-07-01T00:00:00Z/P7D", "2012-07-01/P7D/2012-07-31T00:00:00Z", "R2/2012-07-01T00:00:00Z/7D"} {
+		_, err := ParsePeriod(iso)
+		unequal(t, nil, err)
+	}
+}
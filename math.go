@@ -2,9 +2,7 @@ package utils
 
 import (
 	"math"
-	"math/rand"
 	"strconv"
-	"time"
 )
 
 // Abs — Absolute value
@@ -12,16 +10,15 @@ func Abs(number float64) float64 {
 	return math.Abs(number)
 }
 
-// Rand — Generate a random integer
+// Rand — Generate a random integer between min and max inclusive
+// The number is drawn from the package random source, see SetRandom.
+// It is predictable; use RandomInt for tokens, codes and other secrets.
 func Rand(min, max int) int {
 	if min > max {
 		panic("min: min cannot be greater than max")
 	}
 
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	n := r.Intn(math.MaxInt32)
-
-	return n/((math.MaxInt32+1)/(max-min+1)) + min
+	return int(randRange(getRandom(), int64(min), int64(max)))
 }
 
 // Round — Rounds a float
@@ -0,0 +1,551 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"math"
+	"strconv"
+)
+
+// OrderedMap — An associative array that keeps insertion order, like a PHP array
+// Keys are either int or string. String keys holding a decimal integer ("8") are stored as int,
+// bool keys become 0 or 1, float keys are truncated and nil becomes "", as in PHP.
+// The zero value is an empty map ready to use.
+type OrderedMap struct {
+	keys   []interface{}
+	values map[interface{}]interface{}
+	next   int
+}
+
+// NewOrderedMap — Create an ordered map holding the given values under the keys 0, 1, 2...
+func NewOrderedMap(values ...interface{}) *OrderedMap {
+	m := &OrderedMap{}
+	m.Push(values...)
+	return m
+}
+
+// normalizeKey casts a key the way PHP casts array keys
+func normalizeKey(key interface{}) interface{} {
+	switch k := key.(type) {
+	case nil:
+		return ""
+	case bool:
+		if k {
+			return 1
+		}
+		return 0
+	case string:
+		if i, err := strconv.Atoi(k); err == nil && strconv.Itoa(i) == k {
+			return i
+		}
+		return k
+	case float32:
+		return int(k)
+	case float64:
+		return int(k)
+	}
+
+	if i, ok := phpInt(key); ok {
+		return int(i)
+	}
+
+	panic(fmt.Sprintf("key: illegal offset type %T", key))
+}
+
+func (m *OrderedMap) init() {
+	if m.values == nil {
+		m.values = make(map[interface{}]interface{})
+		m.next = math.MinInt
+	}
+}
+
+// nextIndex returns the key used by the next Push
+func (m *OrderedMap) nextIndex() int {
+	if m.next == math.MinInt {
+		return 0
+	}
+	return m.next
+}
+
+// Len — Count all elements in the map
+func (m *OrderedMap) Len() int {
+	return len(m.keys)
+}
+
+// Get — Return the value stored under key
+func (m *OrderedMap) Get(key interface{}) (interface{}, bool) {
+	v, ok := m.values[normalizeKey(key)]
+	return v, ok
+}
+
+// Has — Checks if the given key exists in the map
+func (m *OrderedMap) Has(key interface{}) bool {
+	_, ok := m.values[normalizeKey(key)]
+	return ok
+}
+
+// Set — Store value under key
+// An existing key keeps its position; a new key is appended.
+func (m *OrderedMap) Set(key, value interface{}) {
+	m.init()
+
+	key = normalizeKey(key)
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+
+	if i, ok := key.(int); ok && i >= m.next && i < math.MaxInt {
+		m.next = i + 1
+	}
+}
+
+// Delete — Remove key from the map
+// As in PHP, the next auto-increment key is not lowered.
+func (m *OrderedMap) Delete(key interface{}) {
+	key = normalizeKey(key)
+	if _, ok := m.values[key]; !ok {
+		return
+	}
+
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Keys — Return all the keys in order
+func (m *OrderedMap) Keys() []interface{} {
+	keys := make([]interface{}, len(m.keys))
+	copy(keys, m.keys)
+	return keys
+}
+
+// Values — Return all the values in order
+func (m *OrderedMap) Values() []interface{} {
+	vals := make([]interface{}, len(m.keys))
+	for i, k := range m.keys {
+		vals[i] = m.values[k]
+	}
+	return vals
+}
+
+// All — Iterate over the key/value pairs in order
+func (m *OrderedMap) All() iter.Seq2[interface{}, interface{}] {
+	return func(yield func(interface{}, interface{}) bool) {
+		for _, k := range m.Keys() {
+			v, ok := m.values[k]
+			if !ok {
+				continue
+			}
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// IsList — Checks whether the keys are 0, 1, 2... in order
+func (m *OrderedMap) IsList() bool {
+	for i, k := range m.keys {
+		if k != i {
+			return false
+		}
+	}
+	return true
+}
+
+// Copy — Return a shallow copy of the map
+func (m *OrderedMap) Copy() *OrderedMap {
+	n := &OrderedMap{}
+	n.init()
+	if m.values != nil {
+		n.next = m.next
+	}
+	for _, k := range m.keys {
+		n.keys = append(n.keys, k)
+		n.values[k] = m.values[k]
+	}
+	return n
+}
+
+// renumber re-indexes the integer keys from 0 and leaves string keys untouched
+func (m *OrderedMap) renumber() {
+	keys, values := m.keys, m.values
+	m.keys, m.values = nil, nil
+	m.init()
+
+	for _, k := range keys {
+		if _, ok := k.(int); ok {
+			m.Set(m.nextIndex(), values[k])
+		} else {
+			m.Set(k, values[k])
+		}
+	}
+}
+
+// Push — Push one or more elements onto the end of the map
+// Returns the new number of elements.
+func (m *OrderedMap) Push(values ...interface{}) int {
+	m.init()
+
+	for _, v := range values {
+		if m.next == math.MaxInt {
+			panic("cannot add element to the array as the next element is already occupied")
+		}
+		m.Set(m.nextIndex(), v)
+	}
+
+	return m.Len()
+}
+
+// Pop — Pop the element off the end of the map
+// Returns nil if the map is empty.
+func (m *OrderedMap) Pop() interface{} {
+	if m.Len() == 0 {
+		return nil
+	}
+
+	key := m.keys[len(m.keys)-1]
+	v := m.values[key]
+	m.Delete(key)
+
+	if i, ok := key.(int); ok && i == m.next-1 {
+		m.next = i
+	}
+
+	return v
+}
+
+// Shift — Shift an element off the beginning of the map
+// Integer keys are re-indexed from 0, string keys are left untouched.
+func (m *OrderedMap) Shift() interface{} {
+	if m.Len() == 0 {
+		return nil
+	}
+
+	key := m.keys[0]
+	v := m.values[key]
+	m.Delete(key)
+	m.renumber()
+
+	return v
+}
+
+// Unshift — Prepend one or more elements to the beginning of the map
+// Integer keys are re-indexed from 0, string keys are left untouched. Returns the new number of elements.
+func (m *OrderedMap) Unshift(values ...interface{}) int {
+	n := NewOrderedMap(values...)
+	for _, k := range m.keys {
+		if _, ok := k.(int); ok {
+			n.Push(m.values[k])
+		} else {
+			n.Set(k, m.values[k])
+		}
+	}
+
+	*m = *n
+	return m.Len()
+}
+
+// bounds resolves PHP style offset and length into positions
+func (m *OrderedMap) bounds(offset, length int) (int, int) {
+	size := m.Len()
+
+	if offset > size {
+		offset = size
+	} else if offset < 0 {
+		if offset += size; offset < 0 {
+			offset = 0
+		}
+	}
+
+	end := size
+	if length < 0 {
+		end = size + length
+	} else if length < size-offset {
+		end = offset + length
+	}
+	if end < offset {
+		end = offset
+	}
+
+	return offset, end
+}
+
+// Slice — Extract a slice of the map
+// A negative offset counts from the end, a negative length stops that many elements from the end.
+// Pass Len() as length to slice through the end. String keys are always preserved;
+// integer keys are re-indexed unless preserveKeys is true.
+func (m *OrderedMap) Slice(offset, length int, preserveKeys bool) *OrderedMap {
+	start, end := m.bounds(offset, length)
+
+	n := &OrderedMap{}
+	n.init()
+	for _, k := range m.keys[start:end] {
+		if _, ok := k.(int); ok && !preserveKeys {
+			n.Push(m.values[k])
+		} else {
+			n.Set(k, m.values[k])
+		}
+	}
+
+	return n
+}
+
+// Splice — Remove a portion of the map and replace it with something else
+// offset and length follow the rules of Slice. The removed elements are returned as a list,
+// and the integer keys of m are re-indexed.
+func (m *OrderedMap) Splice(offset, length int, replacement ...interface{}) *OrderedMap {
+	start, end := m.bounds(offset, length)
+
+	removed := &OrderedMap{}
+	removed.init()
+	n := &OrderedMap{}
+	n.init()
+
+	for i, k := range m.keys {
+		if i == start {
+			n.Push(replacement...)
+		}
+		if i >= start && i < end {
+			removed.Push(m.values[k])
+			continue
+		}
+		if _, ok := k.(int); ok {
+			n.Push(m.values[k])
+		} else {
+			n.Set(k, m.values[k])
+		}
+	}
+	if start == m.Len() {
+		n.Push(replacement...)
+	}
+
+	*m = *n
+	return removed
+}
+
+// Flip — Exchanges all keys with their associated values
+// Values that are neither int nor string cannot be keys and are skipped.
+// When values repeat, the last key wins and stays at the position of the first occurrence.
+func (m *OrderedMap) Flip() *OrderedMap {
+	n := &OrderedMap{}
+	n.init()
+
+	for _, k := range m.keys {
+		switch v := m.values[k]; v.(type) {
+		case int, string:
+			n.Set(v, k)
+		}
+	}
+
+	return n
+}
+
+// Column — Return the values from a single column of the rows in the map
+// Rows may be *OrderedMap, map[interface{}]interface{} or map[string]interface{}; other rows are skipped.
+// A nil columnKey returns the complete rows. A nil indexKey yields a list, otherwise the
+// indexKey column of each row is used as key, falling back to the next index when it is missing.
+func (m *OrderedMap) Column(columnKey, indexKey interface{}) *OrderedMap {
+	n := &OrderedMap{}
+	n.init()
+
+	lookup := func(row interface{}, key interface{}) (interface{}, bool) {
+		switch r := row.(type) {
+		case *OrderedMap:
+			return r.Get(key)
+		case map[interface{}]interface{}:
+			v, ok := r[key]
+			return v, ok
+		case map[string]interface{}:
+			v, ok := r[phpString(key)]
+			return v, ok
+		}
+		return nil, false
+	}
+
+	for _, k := range m.keys {
+		row := m.values[k]
+		switch row.(type) {
+		case *OrderedMap, map[interface{}]interface{}, map[string]interface{}:
+		default:
+			continue
+		}
+
+		value := row
+		if columnKey != nil {
+			v, ok := lookup(row, columnKey)
+			if !ok {
+				continue
+			}
+			value = v
+		}
+
+		if indexKey != nil {
+			if idx, ok := lookup(row, indexKey); ok {
+				switch idx.(type) {
+				case int, string:
+					n.Set(idx, value)
+					continue
+				}
+			}
+		}
+		n.Push(value)
+	}
+
+	return n
+}
+
+// Merge — Merge one or more maps
+// Integer keys are re-indexed in order. A later string key overwrites the value of
+// an earlier one but keeps the earlier position.
+func (m *OrderedMap) Merge(others ...*OrderedMap) *OrderedMap {
+	n := &OrderedMap{}
+	n.init()
+
+	for _, o := range append([]*OrderedMap{m}, others...) {
+		for _, k := range o.keys {
+			if _, ok := k.(int); ok {
+				n.Push(o.values[k])
+			} else {
+				n.Set(k, o.values[k])
+			}
+		}
+	}
+
+	return n
+}
+
+// MarshalJSON — Encode the map as JSON
+// A list is encoded as an array, anything else as an object with the keys in order.
+func (m OrderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	if m.IsList() {
+		buf.WriteByte('[')
+		for i, k := range m.keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			b, err := json.Marshal(m.values[k])
+			if err != nil {
+				return nil, err
+			}
+			buf.Write(b)
+		}
+		buf.WriteByte(']')
+		return buf.Bytes(), nil
+	}
+
+	buf.WriteByte('{')
+	for i, k := range m.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(phpString(k))
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		b, err := json.Marshal(m.values[k])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(b)
+	}
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON — Decode a JSON object or array into the map, keeping the key order
+// Nested objects become *OrderedMap, nested arrays []interface{}, and integral numbers int.
+func (m *OrderedMap) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := tok.(json.Delim)
+	if !ok || (delim != '{' && delim != '[') {
+		return fmt.Errorf("json: cannot unmarshal %v into OrderedMap", tok)
+	}
+
+	n, err := decodeOrderedMap(dec, delim)
+	if err != nil {
+		return err
+	}
+
+	*m = *n
+	return nil
+}
+
+func decodeOrderedMap(dec *json.Decoder, delim json.Delim) (*OrderedMap, error) {
+	m := &OrderedMap{}
+	m.init()
+
+	for dec.More() {
+		var key interface{}
+		if delim == '{' {
+			tok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key = tok.(string)
+		}
+
+		v, err := decodeJSONValue(dec)
+		if err != nil {
+			return nil, err
+		}
+
+		if delim == '{' {
+			m.Set(key, v)
+		} else {
+			m.Push(v)
+		}
+	}
+
+	// closing delimiter
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		n, err := decodeOrderedMap(dec, t)
+		if err != nil {
+			return nil, err
+		}
+		if t == '[' {
+			return n.Values(), nil
+		}
+		return n, nil
+	case json.Number:
+		if i, err := strconv.Atoi(t.String()); err == nil {
+			return i, nil
+		}
+		return t.Float64()
+	}
+
+	return tok, nil
+}
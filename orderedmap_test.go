@@ -0,0 +1,133 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOrderedMap(t *testing.T) {
+	m := &OrderedMap{}
+	m.Set("b", 1)
+	m.Set("8", "eight")
+	m.Set(true, "one")
+	m.Set("a", 2)
+	m.Set(1.7, "uno")
+	equal(t, []interface{}{"b", 8, 1, "a"}, m.Keys())
+	equal(t, []interface{}{1, "eight", "uno", 2}, m.Values())
+	equal(t, 5, m.Push("nine"))
+	equal(t, []interface{}{"b", 8, 1, "a", 9}, m.Keys())
+
+	var keys []interface{}
+	for k := range m.All() {
+		keys = append(keys, k)
+	}
+	equal(t, m.Keys(), keys)
+
+	// array_pop lowers the next index, unset does not
+	equal(t, "nine", m.Pop())
+	m.Push("x")
+	equal(t, 9, m.Keys()[4])
+	m.Delete(9)
+	m.Push("y")
+	equal(t, 10, m.Keys()[4])
+
+	n := NewOrderedMap("a", "b")
+	n.Set("k", "v")
+	n.Set(5, "c")
+	equal(t, "a", n.Shift())
+	equal(t, []interface{}{0, "k", 1}, n.Keys())
+	equal(t, 5, n.Unshift("x", "y"))
+	equal(t, []interface{}{0, 1, 2, "k", 3}, n.Keys())
+	equal(t, []interface{}{"x", "y", "b", "v", "c"}, n.Values())
+	equal(t, 6, n.Push("z"))
+	equal(t, nil, (&OrderedMap{}).Pop())
+	equal(t, nil, (&OrderedMap{}).Shift())
+
+	neg := &OrderedMap{}
+	neg.Set(-5, "a")
+	neg.Push("b")
+	equal(t, []interface{}{-5, -4}, neg.Keys())
+
+	s := NewOrderedMap("a", "b", "c", "d", "e")
+	slices := []struct {
+		offset, length int
+		preserve       bool
+		keys, values   []interface{}
+	}{
+		{2, s.Len(), false, []interface{}{0, 1, 2}, []interface{}{"c", "d", "e"}},
+		{-2, 1, false, []interface{}{0}, []interface{}{"d"}},
+		{0, 3, false, []interface{}{0, 1, 2}, []interface{}{"a", "b", "c"}},
+		{2, -1, true, []interface{}{2, 3}, []interface{}{"c", "d"}},
+		{-10, 2, false, []interface{}{0, 1}, []interface{}{"a", "b"}},
+		{10, 2, false, []interface{}{}, []interface{}{}},
+		{3, -3, false, []interface{}{}, []interface{}{}},
+	}
+	for _, c := range slices {
+		tSlice := s.Slice(c.offset, c.length, c.preserve)
+		equal(t, c.keys, tSlice.Keys())
+		equal(t, c.values, tSlice.Values())
+	}
+
+	sp := NewOrderedMap("red", "green", "blue", "yellow")
+	sp.Set("k", "v")
+	removed := sp.Splice(1, 2, "orange")
+	equal(t, []interface{}{"green", "blue"}, removed.Values())
+	equal(t, []interface{}{0, 1, 2, "k"}, sp.Keys())
+	equal(t, []interface{}{"red", "orange", "yellow", "v"}, sp.Values())
+	sp.Splice(sp.Len(), 0, "purple")
+	equal(t, []interface{}{"red", "orange", "yellow", "v", "purple"}, sp.Values())
+	sp.Splice(-1, 1)
+	equal(t, []interface{}{"red", "orange", "yellow", "v"}, sp.Values())
+
+	f := NewOrderedMap("a", "b", "a", 1.5)
+	tFlip := f.Flip()
+	equal(t, []interface{}{"a", "b"}, tFlip.Keys())
+	equal(t, []interface{}{2, 1}, tFlip.Values())
+
+	rows := NewOrderedMap(
+		map[string]interface{}{"id": 3245, "first_name": "John"},
+		map[interface{}]interface{}{"id": 5342, "first_name": "Sally"},
+		NewOrderedMap(),
+		"not a row",
+	)
+	rows.Values()[2].(*OrderedMap).Set("id", 5623)
+	rows.Values()[2].(*OrderedMap).Set("first_name", "Jane")
+	tColumn := rows.Column("first_name", nil)
+	equal(t, []interface{}{"John", "Sally", "Jane"}, tColumn.Values())
+	tColumn = rows.Column("first_name", "id")
+	equal(t, []interface{}{3245, 5342, 5623}, tColumn.Keys())
+	equal(t, 3, rows.Column(nil, "missing").Len())
+
+	m1 := NewOrderedMap("a", "b")
+	m1.Set("color", "red")
+	m2 := &OrderedMap{}
+	m2.Set(4, "c")
+	m2.Set("color", "green")
+	m2.Set("shape", "trapezoid")
+	tMerge := m1.Merge(m2)
+	equal(t, []interface{}{0, 1, "color", 2, "shape"}, tMerge.Keys())
+	equal(t, []interface{}{"a", "b", "green", "c", "trapezoid"}, tMerge.Values())
+
+	j, err := json.Marshal(tMerge)
+	equal(t, nil, err)
+	equal(t, `{"0":"a","1":"b","color":"green","2":"c","shape":"trapezoid"}`, string(j))
+
+	j, _ = json.Marshal(NewOrderedMap(1, "x", NewOrderedMap()))
+	equal(t, `[1,"x",[]]`, string(j))
+
+	var decoded OrderedMap
+	err = json.Unmarshal([]byte(`{"z":1,"10":2.5,"a":{"y":[1,"b"],"x":null}}`), &decoded)
+	equal(t, nil, err)
+	equal(t, []interface{}{"z", 10, "a"}, decoded.Keys())
+	equal(t, 2.5, decoded.Values()[1])
+	nested := decoded.Values()[2].(*OrderedMap)
+	equal(t, []interface{}{"y", "x"}, nested.Keys())
+	equal(t, []interface{}{[]interface{}{1, "b"}, nil}, nested.Values())
+	decoded.Push(true)
+	equal(t, 11, decoded.Keys()[3])
+
+	j, _ = json.Marshal(&decoded)
+	equal(t, `{"z":1,"10":2.5,"a":{"y":[1,"b"],"x":null},"11":true}`, string(j))
+
+	unequal(t, nil, json.Unmarshal([]byte(`"str"`), &decoded))
+}
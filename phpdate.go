@@ -0,0 +1,585 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// dateChar is an entry of PHP's date() format character table.
+// format renders the character; parse, when set, reads it back for DateCreateFromFormat.
+// Characters without parse must appear literally in the parsed value, as in PHP.
+type dateChar struct {
+	format func(t time.Time) string
+	parse  func(p *dateFromFormat) error
+}
+
+var dateChars map[byte]dateChar
+
+func init() {
+	dateChars = map[byte]dateChar{
+		// day
+		'd': {func(t time.Time) string { return fmt.Sprintf("%02d", t.Day()) }, parseDay},
+		'D': {func(t time.Time) string { return t.Weekday().String()[:3] }, parseDayName},
+		'j': {func(t time.Time) string { return strconv.Itoa(t.Day()) }, parseDay},
+		'l': {func(t time.Time) string { return t.Weekday().String() }, parseDayName},
+		'N': {func(t time.Time) string { return strconv.Itoa(isoWeekday(t)) }, nil},
+		'S': {func(t time.Time) string { return daySuffix(t.Day()) }, parseDaySuffix},
+		'w': {func(t time.Time) string { return strconv.Itoa(int(t.Weekday())) }, nil},
+		'z': {func(t time.Time) string { return strconv.Itoa(t.YearDay() - 1) }, parseDayOfYear},
+
+		// week
+		'W': {func(t time.Time) string {
+			_, week := t.ISOWeek()
+			return fmt.Sprintf("%02d", week)
+		}, nil},
+
+		// month
+		'F': {func(t time.Time) string { return t.Month().String() }, parseMonthName},
+		'm': {func(t time.Time) string { return fmt.Sprintf("%02d", int(t.Month())) }, parseMonth},
+		'M': {func(t time.Time) string { return t.Month().String()[:3] }, parseMonthName},
+		'n': {func(t time.Time) string { return strconv.Itoa(int(t.Month())) }, parseMonth},
+		't': {func(t time.Time) string { return strconv.Itoa(daysInMonth(t.Year(), t.Month())) }, nil},
+
+		// year
+		'L': {func(t time.Time) string {
+			if isLeapYear(t.Year()) {
+				return "1"
+			}
+			return "0"
+		}, nil},
+		'o': {func(t time.Time) string {
+			year, _ := t.ISOWeek()
+			return formatYear(year, false)
+		}, nil},
+		'X': {func(t time.Time) string { return formatYear(t.Year(), true) }, parseExpandedYear},
+		'x': {func(t time.Time) string {
+			if t.Year() >= 10000 || t.Year() < 0 {
+				return formatYear(t.Year(), true)
+			}
+			return formatYear(t.Year(), false)
+		}, parseExpandedYear},
+		'Y': {func(t time.Time) string { return formatYear(t.Year(), false) }, parseYear},
+		'y': {func(t time.Time) string { return fmt.Sprintf("%02d", (t.Year()%100+100)%100) }, parseYear2},
+
+		// time
+		'a': {func(t time.Time) string {
+			if t.Hour() < 12 {
+				return "am"
+			}
+			return "pm"
+		}, parseMeridian},
+		'A': {func(t time.Time) string {
+			if t.Hour() < 12 {
+				return "AM"
+			}
+			return "PM"
+		}, parseMeridian},
+		'B': {swatchTime, nil},
+		'g': {func(t time.Time) string { return strconv.Itoa(hour12(t)) }, parseHour12},
+		'G': {func(t time.Time) string { return strconv.Itoa(t.Hour()) }, parseHour},
+		'h': {func(t time.Time) string { return fmt.Sprintf("%02d", hour12(t)) }, parseHour12},
+		'H': {func(t time.Time) string { return fmt.Sprintf("%02d", t.Hour()) }, parseHour},
+		'i': {func(t time.Time) string { return fmt.Sprintf("%02d", t.Minute()) }, parseMinute},
+		's': {func(t time.Time) string { return fmt.Sprintf("%02d", t.Second()) }, parseSecond},
+		'u': {func(t time.Time) string { return fmt.Sprintf("%06d", t.Nanosecond()/1000) }, parseMicroseconds},
+		'v': {func(t time.Time) string { return fmt.Sprintf("%03d", t.Nanosecond()/1000000) }, parseMilliseconds},
+
+		// timezone
+		'e': {timezoneIdentifier, parseZone},
+		'I': {func(t time.Time) string {
+			if t.IsDST() {
+				return "1"
+			}
+			return "0"
+		}, nil},
+		'O': {func(t time.Time) string { return formatOffset(t, false) }, parseZone},
+		'P': {func(t time.Time) string { return formatOffset(t, true) }, parseZone},
+		'p': {func(t time.Time) string {
+			if _, offset := t.Zone(); offset == 0 {
+				return "Z"
+			}
+			return formatOffset(t, true)
+		}, parseZone},
+		'T': {timezoneAbbreviation, parseZone},
+		'Z': {func(t time.Time) string {
+			_, offset := t.Zone()
+			return strconv.Itoa(offset)
+		}, nil},
+
+		// full date/time
+		'c': {func(t time.Time) string { return DateFormat(t, `Y-m-d\TH:i:sP`) }, nil},
+		'r': {func(t time.Time) string { return DateFormat(t, "D, d M Y H:i:s O") }, nil},
+		'U': {func(t time.Time) string { return strconv.FormatInt(t.Unix(), 10) }, parseUnix},
+	}
+}
+
+func isLeapYear(year int) bool {
+	return year%4 == 0 && (year%100 != 0 || year%400 == 0)
+}
+
+func daysInMonth(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// isoWeekday returns 1 for Monday through 7 for Sunday
+func isoWeekday(t time.Time) int {
+	if t.Weekday() == time.Sunday {
+		return 7
+	}
+	return int(t.Weekday())
+}
+
+func daySuffix(day int) string {
+	if day < 11 || day > 13 {
+		switch day % 10 {
+		case 1:
+			return "st"
+		case 2:
+			return "nd"
+		case 3:
+			return "rd"
+		}
+	}
+	return "th"
+}
+
+func hour12(t time.Time) int {
+	if h := t.Hour() % 12; h != 0 {
+		return h
+	}
+	return 12
+}
+
+// formatYear renders at least 4 digits with a "-" for years BCE, and a "+" for other years when signed
+func formatYear(year int, signed bool) string {
+	switch {
+	case year < 0:
+		return fmt.Sprintf("-%04d", -year)
+	case signed:
+		return fmt.Sprintf("+%04d", year)
+	}
+	return fmt.Sprintf("%04d", year)
+}
+
+// swatchTime returns the Swatch Internet time, 1000 beats a day in UTC+1
+func swatchTime(t time.Time) string {
+	seconds := (t.Unix()%86400 + 86400 + 3600) % 86400
+	return fmt.Sprintf("%03d", seconds*10/864)
+}
+
+func formatOffset(t time.Time, colon bool) string {
+	_, offset := t.Zone()
+
+	sign := '+'
+	if offset < 0 {
+		sign, offset = '-', -offset
+	}
+	if colon {
+		return fmt.Sprintf("%c%02d:%02d", sign, offset/3600, offset%3600/60)
+	}
+	return fmt.Sprintf("%c%02d%02d", sign, offset/3600, offset%3600/60)
+}
+
+func timezoneAbbreviation(t time.Time) string {
+	name, _ := t.Zone()
+	if name == "" {
+		return formatOffset(t, true)
+	}
+	return name
+}
+
+func timezoneIdentifier(t time.Time) string {
+	name := t.Location().String()
+	if name == "Local" {
+		name = os.Getenv("TZ")
+	}
+	if name == "" {
+		return timezoneAbbreviation(t)
+	}
+	return name
+}
+
+// DateFormat — Format t according to PHP's date() format characters
+// Characters not in the table are copied, and a backslash escapes the next character.
+// DateFormat(t, "l jS \of F Y h:i:s A") == "Friday 27th of April 2018 10:23:14 AM"
+func DateFormat(t time.Time, format string) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c == '\\' && i+1 < len(format) {
+			i++
+			b.WriteByte(format[i])
+			continue
+		}
+
+		if char, ok := dateChars[c]; ok {
+			b.WriteString(char.format(t))
+		} else {
+			b.WriteByte(c)
+		}
+	}
+
+	return b.String()
+}
+
+// PhpDate — Format a local time/date like PHP's date()
+// The time is formatted in the package default timezone, see SetLocation.
+// PhpDate("Y-m-d H:i:s", 1524799394) == "2018-04-27 10:23:14" (Asia/Jakarta)
+// PhpDate("D, d M Y", 1524799394) == "Fri, 27 Apr 2018"
+func PhpDate(format string, timestamp int64) string {
+	return DateFormat(time.Unix(timestamp, 0).In(getLocation()), format)
+}
+
+// dateFromFormat holds the state of DateCreateFromFormat
+type dateFromFormat struct {
+	value                string
+	pos                  int
+	y, m, d, h, i, s, us int
+	doy                  int
+	loc                  *time.Location
+}
+
+func (p *dateFromFormat) peek() byte {
+	if p.pos < len(p.value) {
+		return p.value[p.pos]
+	}
+	return 0
+}
+
+// digits reads between 1 and max digits
+func (p *dateFromFormat) digits(max int) (int, int) {
+	start := p.pos
+	for p.pos < len(p.value) && p.pos-start < max && p.peek() >= '0' && p.peek() <= '9' {
+		p.pos++
+	}
+	if start == p.pos {
+		return timeUnset, 0
+	}
+
+	n, _ := strconv.Atoi(p.value[start:p.pos])
+	return n, p.pos - start
+}
+
+func (p *dateFromFormat) number(max int, what string) (int, error) {
+	n, _ := p.digits(max)
+	if n == timeUnset {
+		return 0, fmt.Errorf("%s could not be found at position %d", what, p.pos)
+	}
+	return n, nil
+}
+
+func (p *dateFromFormat) signedNumber(max int, what string) (int, error) {
+	sign := 1
+	if p.peek() == '-' || p.peek() == '+' {
+		if p.peek() == '-' {
+			sign = -1
+		}
+		p.pos++
+	}
+
+	n, err := p.number(max, what)
+	return sign * n, err
+}
+
+// letters reads the next run of ASCII letters in lower case
+func (p *dateFromFormat) letters() string {
+	start := p.pos
+	for c := p.peek(); c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'; c = p.peek() {
+		p.pos++
+	}
+	return toLowerASCII(p.value[start:p.pos])
+}
+
+func parseDay(p *dateFromFormat) (err error) {
+	p.d, err = p.number(2, "a two digit day")
+	return err
+}
+
+func parseDayName(p *dateFromFormat) error {
+	if unit, ok := relativeUnits[p.letters()]; !ok || unit.field != 'w' {
+		return fmt.Errorf("a textual day could not be found at position %d", p.pos)
+	}
+	return nil
+}
+
+func parseDaySuffix(p *dateFromFormat) error {
+	switch p.letters() {
+	case "st", "nd", "rd", "th":
+		return nil
+	}
+	return fmt.Errorf("a day suffix could not be found at position %d", p.pos)
+}
+
+func parseDayOfYear(p *dateFromFormat) (err error) {
+	if p.y == timeUnset {
+		return fmt.Errorf("a 'day of year' can only come after a year has been found")
+	}
+	p.doy, err = p.number(3, "a three digit day-of-year")
+	return err
+}
+
+func parseMonth(p *dateFromFormat) (err error) {
+	p.m, err = p.number(2, "a two digit month")
+	return err
+}
+
+func parseMonthName(p *dateFromFormat) error {
+	word := p.letters()
+	if m, ok := monthNames[word]; ok && strings.Trim(word, "ivx") != "" {
+		p.m = m
+		return nil
+	}
+	return fmt.Errorf("a textual month could not be found at position %d", p.pos)
+}
+
+func parseYear(p *dateFromFormat) (err error) {
+	p.y, err = p.signedNumber(4, "a four digit year")
+	return err
+}
+
+func parseYear2(p *dateFromFormat) error {
+	y, length := p.digits(2)
+	if y == timeUnset {
+		return fmt.Errorf("a two digit year could not be found at position %d", p.pos)
+	}
+	p.y = processYear(y, length)
+	return nil
+}
+
+func parseExpandedYear(p *dateFromFormat) (err error) {
+	p.y, err = p.signedNumber(19, "an expanded year")
+	return err
+}
+
+func parseMeridian(p *dateFromFormat) error {
+	if p.h == timeUnset {
+		return fmt.Errorf("meridian can only come after an hour has been found")
+	}
+
+	start := p.pos
+	word := p.letters()
+	if word == "" && p.pos+4 <= len(p.value) {
+		// a.m. and p.m.
+		word = toLowerASCII(strings.Replace(p.value[p.pos:p.pos+4], ".", "", -1))
+		p.pos += 4
+	}
+
+	switch word {
+	case "am":
+		if p.h == 12 {
+			p.h = 0
+		}
+	case "pm":
+		if p.h != 12 {
+			p.h += 12
+		}
+	default:
+		return fmt.Errorf("a meridian could not be found at position %d", start)
+	}
+	return nil
+}
+
+func parseHour12(p *dateFromFormat) (err error) {
+	if p.h, err = p.number(2, "a two digit hour"); err == nil && p.h > 12 {
+		err = fmt.Errorf("hour cannot be higher than 12")
+	}
+	return err
+}
+
+func parseHour(p *dateFromFormat) (err error) {
+	p.h, err = p.number(2, "a two digit hour")
+	return err
+}
+
+func parseMinute(p *dateFromFormat) error {
+	i, length := p.digits(2)
+	if length != 2 {
+		return fmt.Errorf("a two digit minute could not be found at position %d", p.pos)
+	}
+	p.i = i
+	return nil
+}
+
+func parseSecond(p *dateFromFormat) error {
+	s, length := p.digits(2)
+	if length != 2 {
+		return fmt.Errorf("a two digit second could not be found at position %d", p.pos)
+	}
+	p.s = s
+	return nil
+}
+
+func parseMicroseconds(p *dateFromFormat) error {
+	start := p.pos
+	if _, length := p.digits(6); length == 0 {
+		return fmt.Errorf("a six digit microsecond could not be found at position %d", p.pos)
+	}
+
+	digits := p.value[start:p.pos]
+	p.us, _ = strconv.Atoi(digits + strings.Repeat("0", 6-len(digits)))
+	return nil
+}
+
+func parseMilliseconds(p *dateFromFormat) error {
+	ms, length := p.digits(3)
+	if length != 3 {
+		return fmt.Errorf("a three digit millisecond could not be found at position %d", p.pos)
+	}
+	p.us = ms * 1000
+	return nil
+}
+
+func parseZone(p *dateFromFormat) error {
+	start := p.pos
+	for p.pos < len(p.value) && strings.IndexByte(" ,;", p.peek()) == -1 {
+		p.pos++
+	}
+
+	loc, err := parseTimezone(p.value[start:p.pos])
+	if err != nil {
+		if loc, err = time.LoadLocation(p.value[start:p.pos]); err != nil {
+			return fmt.Errorf("the timezone could not be found in the database at position %d", start)
+		}
+	}
+	p.loc = loc
+	return nil
+}
+
+func parseUnix(p *dateFromFormat) error {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	if _, length := p.digits(19); length == 0 {
+		return fmt.Errorf("a unix timestamp could not be found at position %d", start)
+	}
+
+	ts, err := strconv.ParseInt(p.value[start:p.pos], 10, 64)
+	if err != nil {
+		return err
+	}
+
+	t := time.Unix(ts, 0).UTC()
+	p.y, p.m, p.d = t.Year(), int(t.Month()), t.Day()
+	p.h, p.i, p.s = t.Hour(), t.Minute(), t.Second()
+	p.loc = time.UTC
+	return nil
+}
+
+// reset sets the fields to the Unix epoch, only those not parsed yet unless all is set
+// The timezone is not part of the reset: like in PHP, it stays the default one unless value has one.
+func (p *dateFromFormat) reset(all bool) {
+	fields := []*int{&p.y, &p.m, &p.d, &p.h, &p.i, &p.s, &p.us}
+	epoch := []int{1970, 1, 1, 0, 0, 0, 0}
+	for i, f := range fields {
+		if all || *f == timeUnset {
+			*f = epoch[i]
+		}
+	}
+}
+
+// DateCreateFromFormat — Parse a time string according to PHP's date() format characters
+// Fields missing from format are taken from the current time, unless format contains
+// "!" (reset all fields to the Unix epoch) or "|" (reset the fields not parsed yet); the reset
+// fields give midnight, January 1st 1970 in the timezone of the result.
+// Besides the format characters, format may contain:
+// "#" one of ;:/.,-(), "?" any byte, "*" any bytes up to the next separator or digit,
+// "+" ignore trailing data, "\" escape the next character.
+// Without a timezone in value, the time is in the package default timezone, see SetLocation.
+// DateCreateFromFormat("d/m/Y H:i", "15/08/2018 10:30")
+func DateCreateFromFormat(format, value string) (time.Time, error) {
+	p := &dateFromFormat{value: value, doy: timeUnset}
+	p.y, p.m, p.d, p.h, p.i, p.s, p.us = timeUnset, timeUnset, timeUnset, timeUnset, timeUnset, timeUnset, timeUnset
+
+	trailing := false
+	for i := 0; i < len(format) && !trailing; i++ {
+		c := format[i]
+
+		var err error
+		switch c {
+		case '!':
+			p.reset(true)
+		case '|':
+			p.reset(false)
+		case '+':
+			trailing = true
+		case ' ':
+			for p.peek() == ' ' || p.peek() == '\t' {
+				p.pos++
+			}
+		case '#':
+			if strings.IndexByte(";:/.,-()", p.peek()) == -1 || p.pos >= len(value) {
+				err = fmt.Errorf("the separation symbol ([;:/.,-]) could not be found at position %d", p.pos)
+			} else {
+				p.pos++
+			}
+		case '?':
+			if p.pos >= len(value) {
+				err = fmt.Errorf("unexpected data found at position %d", p.pos)
+			} else {
+				p.pos++
+			}
+		case '*':
+			for p.pos < len(value) && strings.IndexByte(" ;:/.,-()0123456789", p.peek()) == -1 {
+				p.pos++
+			}
+		default:
+			if char, ok := dateChars[c]; ok && char.parse != nil {
+				err = char.parse(p)
+				break
+			}
+			if c == '\\' && i+1 < len(format) {
+				i++
+				c = format[i]
+			}
+			if p.peek() != c || p.pos >= len(value) {
+				err = fmt.Errorf("the format separator does not match at position %d", p.pos)
+			} else {
+				p.pos++
+			}
+		}
+
+		if err != nil {
+			return time.Time{}, fmt.Errorf("date create from format: %v", err)
+		}
+	}
+
+	if p.pos < len(value) && !trailing {
+		return time.Time{}, fmt.Errorf("date create from format: trailing data at position %d", p.pos)
+	}
+
+	if p.h != timeUnset || p.i != timeUnset || p.s != timeUnset || p.us != timeUnset {
+		for _, f := range []*int{&p.h, &p.i, &p.s, &p.us} {
+			if *f == timeUnset {
+				*f = 0
+			}
+		}
+	}
+
+	loc := p.loc
+	if loc == nil {
+		loc = getLocation()
+	}
+	now := getClock().Now().In(loc)
+	fill := func(v *int, def int) {
+		if *v == timeUnset {
+			*v = def
+		}
+	}
+	fill(&p.y, now.Year())
+	fill(&p.m, int(now.Month()))
+	fill(&p.d, now.Day())
+	fill(&p.h, now.Hour())
+	fill(&p.i, now.Minute())
+	fill(&p.s, now.Second())
+	fill(&p.us, now.Nanosecond()/1000)
+
+	if p.doy != timeUnset {
+		p.m, p.d = 1, p.doy+1
+	}
+
+	return time.Date(p.y, time.Month(p.m), p.d, p.h, p.i, p.s, p.us*1000, loc), nil
+}
@@ -0,0 +1,148 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPhpDate(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+	tm := time.Date(2018, 4, 27, 10, 23, 14, 123456000, jakarta)
+
+	formats := map[string]string{
+		"Y-m-d H:i:s":              "2018-04-27 10:23:14",
+		"D, d M Y":                 "Fri, 27 Apr 2018",
+		"l jS \\of F Y h:i:s A":    "Friday 27th of April 2018 10:23:14 AM",
+		"N w z t L":                "5 5 116 30 0",
+		"W o":                      "17 2018",
+		"g G h H a":                "10 10 10 10 am",
+		"u v":                      "123456 123",
+		"e T O P p Z I":            "WIB WIB +0700 +07:00 +07:00 25200 0",
+		"U":                        "1524799394",
+		"B":                        "182",
+		"c":                        "2018-04-27T10:23:14+07:00",
+		"r":                        "Fri, 27 Apr 2018 10:23:14 +0700",
+		"y X x":                    "18 +2018 2018",
+		`\Y\m\d \\ q`:              `Ymd \ q`,
+		"jS jS jS jS jS jS jS":     "27th 27th 27th 27th 27th 27th 27th",
+		"[H]:[i] {\\T}":            "[10]:[23] {T}",
+		"D, d M Y H:i:s \\G\\M\\T": "Fri, 27 Apr 2018 10:23:14 GMT",
+	}
+	for format, expected := range formats {
+		equal(t, expected, DateFormat(tm, format))
+	}
+
+	for day, suffix := range map[int]string{1: "st", 2: "nd", 3: "rd", 4: "th", 11: "th", 12: "th", 13: "th", 21: "st", 22: "nd", 23: "rd", 31: "st"} {
+		equal(t, suffix, DateFormat(time.Date(2018, 1, day, 0, 0, 0, 0, time.UTC), "S"))
+	}
+
+	// ISO years and weeks differ from calendar years around new year
+	equal(t, "2020-W53-5", DateFormat(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), `o-\WW-N`))
+	equal(t, "2025-W01-1", DateFormat(time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC), `o-\WW-N`))
+	equal(t, "1 29 59", DateFormat(time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC), "L t z"))
+	equal(t, "p Z 000", DateFormat(time.Date(2020, 2, 29, 23, 0, 0, 0, time.UTC), `\p p B`))
+	equal(t, "12 12 am pm", DateFormat(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), "g h a ")+DateFormat(time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), "a"))
+	equal(t, "-0044 -0044 -0044", DateFormat(time.Date(-44, 3, 15, 0, 0, 0, 0, time.UTC), "Y X x"))
+	equal(t, "+10191 +10191 10191", DateFormat(time.Date(10191, 1, 1, 0, 0, 0, 0, time.UTC), "X x Y"))
+	equal(t, "+05:30 +05:30", DateFormat(time.Date(2020, 1, 1, 0, 0, 0, 0, time.FixedZone("", 19800)), "T e"))
+
+	defer SetLocation(SetLocation(jakarta))
+	equal(t, "2018-04-27 10:23:14", PhpDate("Y-m-d H:i:s", 1524799394))
+}
+
+func TestDateCreateFromFormat(t *testing.T) {
+	jakarta := time.FixedZone("WIB", 7*3600)
+	defer SetLocation(SetLocation(jakarta))
+
+	parsed := []struct {
+		format, value, expected string
+	}{
+		{"Y-m-d H:i:s", "2018-04-27 10:23:14", "2018-04-27 10:23:14.000000 +0700"},
+		{"!d/m/Y", "15/08/2018", "2018-08-15 00:00:00.000000 +0700"},
+		{"Y-m-d|", "2018-08-15", "2018-08-15 00:00:00.000000 +0700"},
+		{"!j-n-y", "5-8-18", "2018-08-05 00:00:00.000000 +0700"},
+		{"!D, d M Y", "Wed, 15 Aug 2018", "2018-08-15 00:00:00.000000 +0700"},
+		{"!l jS F Y", "Wednesday 15th August 2018", "2018-08-15 00:00:00.000000 +0700"},
+		{"!Y z", "2018 226", "2018-08-15 00:00:00.000000 +0700"},
+		{"!Y-m-d g:i a", "2018-08-15 12:05 am", "2018-08-15 00:05:00.000000 +0700"},
+		{"!Y-m-d h:i:s A", "2018-08-15 07:05:09 PM", "2018-08-15 19:05:09.000000 +0700"},
+		{"Y-m-d H:i:s.u", "2018-08-15 19:05:09.5", "2018-08-15 19:05:09.500000 +0700"},
+		{"Y-m-d H:i:s.v", "2018-08-15 19:05:09.123", "2018-08-15 19:05:09.123000 +0700"},
+		{"Y-m-d H:i:s P", "2018-08-15 19:05:09 -03:00", "2018-08-15 19:05:09.000000 -0300"},
+		{"Y-m-d H:i:s O", "2018-08-15 19:05:09 +0530", "2018-08-15 19:05:09.000000 +0530"},
+		{"Y-m-d H:i:s T", "2018-08-15 19:05:09 EST", "2018-08-15 19:05:09.000000 -0500"},
+		{"Y-m-d H:i:s e", "2018-08-15 19:05:09 Europe/Amsterdam", "2018-08-15 19:05:09.000000 +0200"},
+		{"Y-m-d\\TH:i:sp", "2018-08-15T19:05:09Z", "2018-08-15 19:05:09.000000 +0000"},
+		{"U", "1524799394", "2018-04-27 03:23:14.000000 +0000"},
+		{"!Y#m#d", "2018.08/15", "2018-08-15 00:00:00.000000 +0700"},
+		{"!Y-m-d ??? *", "2018-08-15 abc xyz", "2018-08-15 00:00:00.000000 +0700"},
+		{"!Y-m-d+", "2018-08-15 trailing", "2018-08-15 00:00:00.000000 +0700"},
+		{"!\\Y\\e\\a\\r: Y", "Year: 2018", "2018-01-01 00:00:00.000000 +0700"},
+		{"!Y-m-d H", "2018-02-31 24", "2018-03-04 00:00:00.000000 +0700"},
+	}
+	for _, c := range parsed {
+		tm, err := DateCreateFromFormat(c.format, c.value)
+		equal(t, nil, err)
+		equal(t, c.expected, tm.Format("2006-01-02 15:04:05.000000 -0700"))
+	}
+
+	// missing fields come from the current time
+	now := time.Now().In(jakarta)
+	tm, _ := DateCreateFromFormat("H:i", "10:30")
+	equal(t, now.Year(), tm.Year())
+	equal(t, now.YearDay(), tm.YearDay())
+	equal(t, 0, tm.Second())
+
+	invalid := [][2]string{
+		{"Y-m-d", "2018-08"},
+		{"Y-m-d", "2018-08-15 10:00"},
+		{"H:i", "10:5"},
+		{"h:i", "13:05"},
+		{"a H", "am 10"},
+		{"D", "Xyz"},
+		{"F", "vi"},
+		{"Y#m", "2018m08"},
+		{"e", "Mars/Olympus"},
+		{"z Y", "10 2018"},
+	}
+	// the zone of value is kept by a reset, which does not force UTC
+	tm, _ = DateCreateFromFormat("Y-m-d O|", "2018-08-15 -0300")
+	equal(t, "2018-08-15 00:00:00 -0300", tm.Format("2006-01-02 15:04:05 -0700"))
+	tm, _ = DateCreateFromFormat("!Y-m-d", "2018-08-15")
+	equal(t, jakarta, tm.Location())
+
+	for _, c := range invalid {
+		_, err := DateCreateFromFormat(c[0], c[1])
+		unequal(t, nil, err)
+	}
+
+	// round trip through DateFormat
+	times := []time.Time{
+		time.Date(2018, 4, 27, 10, 23, 14, 0, jakarta),
+		time.Date(2000, 2, 29, 0, 0, 0, 0, time.UTC),
+		time.Date(1999, 12, 31, 23, 59, 59, 999999000, time.FixedZone("", -9000)),
+		time.Date(2024, 1, 1, 12, 0, 1, 0, time.FixedZone("", 19800)),
+		time.Date(1969, 7, 20, 20, 17, 40, 0, time.UTC),
+	}
+	formats := []string{
+		"Y-m-d H:i:s.u P",
+		"D, d M Y H:i:s.u O",
+		"l jS F Y g:i:s.u a P",
+		`Y-m-d\TH:i:s.up`,
+		"!Y z H:i:s.u O",
+		"U.u",
+		"!d/m/Y h:i:s.u A P",
+		"X-n-j G:i:s.u P",
+	}
+	for _, tm := range times {
+		for _, format := range formats {
+			str := DateFormat(tm, format)
+			if format[0] == '!' {
+				str = DateFormat(tm, format[1:])
+			}
+			parsed, err := DateCreateFromFormat(format, str)
+			equal(t, nil, err)
+			equal(t, true, tm.Equal(parsed))
+		}
+	}
+}
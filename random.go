@@ -0,0 +1,351 @@
+package utils
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Random — A source of uniformly distributed 32-bit random numbers
+// Rand, MtRand, Shuffle, StrShuffle and ArrayRand draw from the package source, see SetRandom.
+type Random interface {
+	Uint32() uint32
+}
+
+var (
+	randomMu sync.RWMutex
+	random   Random = NewMersenneTwister(randomSeed())
+)
+
+// randomSeed returns an unpredictable seed for the default source
+func randomSeed() uint32 {
+	var b [4]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		return uint32(getClock().Now().UnixNano())
+	}
+	return binary.LittleEndian.Uint32(b[:])
+}
+
+// SetRandom — Replace the package random source and return the previous one
+// Passing nil restores a randomly seeded Mersenne Twister.
+// Usage in tests:
+// defer SetRandom(SetRandom(NewMersenneTwister(42)))
+func SetRandom(r Random) Random {
+	if r == nil {
+		r = NewMersenneTwister(randomSeed())
+	}
+
+	randomMu.Lock()
+	defer randomMu.Unlock()
+
+	prev := random
+	random = r
+	return prev
+}
+
+// getRandom returns the package random source
+func getRandom() Random {
+	randomMu.RLock()
+	defer randomMu.RUnlock()
+	return random
+}
+
+// MersenneTwister — The MT19937 generator used by PHP's mt_rand
+// Seeded with the same value, it produces the same sequence as PHP 7.1 and later.
+// It is safe for concurrent use.
+type MersenneTwister struct {
+	mu    sync.Mutex
+	state [624]uint32
+	next  int
+}
+
+// NewMersenneTwister — Create a Mersenne Twister seeded with seed
+func NewMersenneTwister(seed uint32) *MersenneTwister {
+	mt := &MersenneTwister{}
+	mt.Seed(seed)
+	return mt
+}
+
+// Seed — Reset the generator to the sequence of seed
+func (mt *MersenneTwister) Seed(seed uint32) {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+
+	mt.state[0] = seed
+	for i := 1; i < len(mt.state); i++ {
+		prev := mt.state[i-1]
+		mt.state[i] = 1812433253*(prev^(prev>>30)) + uint32(i)
+	}
+	mt.reload()
+}
+
+// reload regenerates the state vector
+func (mt *MersenneTwister) reload() {
+	const n, m = 624, 397
+
+	twist := func(m, u, v uint32) uint32 {
+		mixed := (u & 0x80000000) | (v & 0x7fffffff)
+		return m ^ (mixed >> 1) ^ (-(v & 1) & 0x9908b0df)
+	}
+
+	s := &mt.state
+	for i := 0; i < n-m; i++ {
+		s[i] = twist(s[i+m], s[i], s[i+1])
+	}
+	for i := n - m; i < n-1; i++ {
+		s[i] = twist(s[i+m-n], s[i], s[i+1])
+	}
+	s[n-1] = twist(s[m-1], s[n-1], s[0])
+
+	mt.next = 0
+}
+
+// Uint32 — Return the next 32-bit number of the sequence
+func (mt *MersenneTwister) Uint32() uint32 {
+	mt.mu.Lock()
+	defer mt.mu.Unlock()
+
+	if mt.next == len(mt.state) {
+		mt.reload()
+	}
+
+	s := mt.state[mt.next]
+	mt.next++
+
+	s ^= s >> 11
+	s ^= (s << 7) & 0x9d2c5680
+	s ^= (s << 15) & 0xefc60000
+	return s ^ (s >> 18)
+}
+
+type cryptoRandom struct{}
+
+func (cryptoRandom) Uint32() uint32 {
+	var b [4]byte
+	if _, err := crand.Read(b[:]); err != nil {
+		panic(err)
+	}
+	return binary.LittleEndian.Uint32(b[:])
+}
+
+// NewCryptoRandom — Create a random source backed by crypto/rand
+// Its output cannot be reproduced or predicted.
+func NewCryptoRandom() Random {
+	return cryptoRandom{}
+}
+
+// randRange returns a uniformly distributed number in [min, max] without modulo bias,
+// consuming r exactly like PHP's php_mt_rand_range
+func randRange(r Random, min, max int64) int64 {
+	umax := uint64(max) - uint64(min)
+
+	if umax > math.MaxUint32 {
+		result := uint64(r.Uint32())<<32 | uint64(r.Uint32())
+		if umax == math.MaxUint64 {
+			return int64(uint64(min) + result)
+		}
+
+		umax++
+		if umax&(umax-1) == 0 {
+			return int64(uint64(min) + result&(umax-1))
+		}
+
+		limit := math.MaxUint64 - (math.MaxUint64 % umax) - 1
+		for result > limit {
+			result = uint64(r.Uint32())<<32 | uint64(r.Uint32())
+		}
+		return int64(uint64(min) + result%umax)
+	}
+
+	result := r.Uint32()
+	if umax == math.MaxUint32 {
+		return int64(uint64(min) + uint64(result))
+	}
+
+	u := uint32(umax) + 1
+	if u&(u-1) == 0 {
+		return int64(uint64(min) + uint64(result&(u-1)))
+	}
+
+	limit := math.MaxUint32 - (math.MaxUint32 % u) - 1
+	for result > limit {
+		result = r.Uint32()
+	}
+	return int64(uint64(min) + uint64(result%u))
+}
+
+// MtSrand — Seed the Mersenne Twister random number generator
+// It installs a new MersenneTwister as the package random source, so Rand, Shuffle,
+// StrShuffle and ArrayRand become reproducible too.
+func MtSrand(seed uint32) {
+	SetRandom(NewMersenneTwister(seed))
+}
+
+// MtGetRandMax — Show largest possible random value
+func MtGetRandMax() int {
+	return math.MaxInt32
+}
+
+// MtRand — Generate a random value via the Mersenne Twister Random Number Generator
+// MtRand() returns a number between 0 and MtGetRandMax(), MtRand(min, max) a number between min
+// and max inclusive.
+func MtRand(minMax ...int) int {
+	r := getRandom()
+
+	switch len(minMax) {
+	case 0:
+		return int(r.Uint32() >> 1)
+	case 2:
+		if minMax[0] > minMax[1] {
+			panic("max: max must be greater than or equal to min")
+		}
+		return int(randRange(r, int64(minMax[0]), int64(minMax[1])))
+	}
+
+	panic("minMax: expected either no arguments or min and max")
+}
+
+// RandomInt — Generates cryptographically secure pseudo-random integers
+// Like PHP's random_int, it always reads crypto/rand and is not affected by SetRandom or MtSrand.
+func RandomInt(min, max int) int {
+	if min > max {
+		panic("min: min cannot be greater than max")
+	}
+
+	return int(randRange(cryptoRandom{}, int64(min), int64(max)))
+}
+
+// Shuffle — Shuffle an array
+// The array is shuffled in place with the same algorithm as PHP's shuffle.
+func Shuffle(s []interface{}) {
+	shuffle(getRandom(), len(s), func(i, j int) {
+		s[i], s[j] = s[j], s[i]
+	})
+}
+
+// shuffle is the Fisher-Yates shuffle used by PHP's shuffle and str_shuffle
+func shuffle(r Random, n int, swap func(i, j int)) {
+	for left := n - 1; left > 0; left-- {
+		if j := int(randRange(r, 0, int64(left))); j != left {
+			swap(left, j)
+		}
+	}
+}
+
+// Character classes for RandomString and RandomPassword
+const (
+	CharsLower        = "abcdefghijklmnopqrstuvwxyz"
+	CharsUpper        = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	CharsDigits       = "0123456789"
+	CharsSymbols      = "!#$%&()*+,-./:;<=>?@[]^_{|}~"
+	CharsAlphanumeric = CharsLower + CharsUpper + CharsDigits
+	CharsURLSafe      = CharsAlphanumeric + "-_"
+)
+
+// RandomBytes — Generates cryptographically secure pseudo-random bytes
+func RandomBytes(length int) []byte {
+	if length < 1 {
+		panic("length: must be greater than 0")
+	}
+
+	b := make([]byte, length)
+	if _, err := crand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return b
+}
+
+var (
+	uniqidMu   sync.Mutex
+	uniqidLast int64
+)
+
+// Uniqid — Generate a time-based unique identifier
+// The identifier is prefix followed by 13 hexadecimal characters of the current time in microseconds.
+// With moreEntropy, a random number below 10 with 8 decimals is appended: "5cfd0b1d4e2a36.81437652".
+// Identifiers are unique within the process but predictable; use RandomString for secrets.
+func Uniqid(prefix string, moreEntropy bool) string {
+	uniqidMu.Lock()
+	usec := getClock().Now().UnixMicro()
+	if usec <= uniqidLast {
+		usec = uniqidLast + 1
+	}
+	uniqidLast = usec
+	uniqidMu.Unlock()
+
+	id := fmt.Sprintf("%s%08x%05x", prefix, usec/1e6, usec%1e6)
+	if moreEntropy {
+		id += fmt.Sprintf("%.8f", float64(randRange(cryptoRandom{}, 0, 999999999))/1e8)
+	}
+
+	return id
+}
+
+// RandomString — Generate a cryptographically secure random string
+// Every character is drawn uniformly from alphabet, which may contain multibyte characters.
+// RandomString(32, CharsURLSafe)
+func RandomString(length int, alphabet string) string {
+	chars := []rune(alphabet)
+	if len(chars) == 0 {
+		panic("alphabet: cannot be an empty string")
+	}
+
+	s := make([]rune, length)
+	for i := range s {
+		s[i] = chars[randRange(cryptoRandom{}, 0, int64(len(chars)-1))]
+	}
+
+	return string(s)
+}
+
+// RandomToken — Generate a cryptographically secure hexadecimal token from length random bytes
+// Same as bin2hex(random_bytes(length)) in PHP.
+func RandomToken(length int) string {
+	return hex.EncodeToString(RandomBytes(length))
+}
+
+// RandomPassword — Generate a cryptographically secure password
+// The password contains at least one character of every class, the remaining characters
+// are drawn uniformly from all classes together. Without classes, CharsLower, CharsUpper,
+// CharsDigits and CharsSymbols are required.
+// RandomPassword(16, CharsLower, CharsUpper, CharsDigits)
+func RandomPassword(length int, classes ...string) string {
+	if len(classes) == 0 {
+		classes = []string{CharsLower, CharsUpper, CharsDigits, CharsSymbols}
+	}
+	if length < len(classes) {
+		panic("length: cannot be less than the number of character classes")
+	}
+
+	var all []rune
+	seen := make(map[rune]bool)
+	password := make([]rune, 0, length)
+	for _, class := range classes {
+		chars := []rune(class)
+		if len(chars) == 0 {
+			panic("classes: cannot contain an empty string")
+		}
+
+		password = append(password, chars[randRange(cryptoRandom{}, 0, int64(len(chars)-1))])
+		for _, c := range chars {
+			if !seen[c] {
+				seen[c] = true
+				all = append(all, c)
+			}
+		}
+	}
+
+	for len(password) < length {
+		password = append(password, all[randRange(cryptoRandom{}, 0, int64(len(all)-1))])
+	}
+
+	shuffle(cryptoRandom{}, len(password), func(i, j int) {
+		password[i], password[j] = password[j], password[i]
+	})
+
+	return string(password)
+}
@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestRandom(t *testing.T) {
+	defer SetRandom(SetRandom(nil))
+
+	// reference values of MT19937
+	mt := NewMersenneTwister(5489)
+	equal(t, uint32(3499211612), mt.Uint32())
+	for i := 2; i < 10000; i++ {
+		mt.Uint32()
+	}
+	equal(t, uint32(4123659995), mt.Uint32())
+
+	// mt_srand(1); mt_rand(); mt_rand();
+	MtSrand(1)
+	equal(t, 895547922, MtRand())
+	equal(t, 2141438069, MtRand())
+
+	MtSrand(42)
+	first := []int{MtRand(1, 6), MtRand(1, 6), MtRand(-5, 5), MtRand(0, MtGetRandMax())}
+	MtSrand(42)
+	second := []int{MtRand(1, 6), MtRand(1, 6), MtRand(-5, 5), MtRand(0, MtGetRandMax())}
+	equal(t, first, second)
+
+	for i := 0; i < 1000; i++ {
+		rangeValue(t, 1, 6, float64(MtRand(1, 6)))
+		rangeValue(t, -3, 3, float64(Rand(-3, 3)))
+		rangeValue(t, 10, 12, float64(RandomInt(10, 12)))
+	}
+	equal(t, 7, MtRand(7, 7))
+	equal(t, 7, RandomInt(7, 7))
+
+	s1 := []interface{}{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	s2 := append([]interface{}(nil), s1...)
+	MtSrand(7)
+	Shuffle(s1)
+	tStrShuffle1 := StrShuffle("abcdef")
+	tArrayRand1 := ArrayRand(s1, 4)
+	MtSrand(7)
+	Shuffle(s2)
+	tStrShuffle2 := StrShuffle("abcdef")
+	tArrayRand2 := ArrayRand(s2, 4)
+	equal(t, s1, s2)
+	equal(t, tStrShuffle1, tStrShuffle2)
+	equal(t, tArrayRand1, tArrayRand2)
+	equal(t, true, sort.IntsAreSorted(tArrayRand1))
+
+	sorted := make([]int, len(s1))
+	for i, v := range s1 {
+		sorted[i] = v.(int)
+	}
+	sort.Ints(sorted)
+	equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, sorted)
+
+	equal(t, []int{0, 1, 2}, ArrayRand([]interface{}{"a", "b", "c"}, 3))
+
+	// every key is picked with the same probability
+	counts := make([]int, 5)
+	for i := 0; i < 5000; i++ {
+		for _, k := range ArrayRand([]interface{}{1, 2, 3, 4, 5}, 2) {
+			counts[k]++
+		}
+	}
+	for _, c := range counts {
+		rangeValue(t, 1800, 2200, float64(c))
+	}
+
+	SetRandom(NewCryptoRandom())
+	rangeValue(t, 0, 100, float64(Rand(0, 100)))
+}
+
+// chiSquare returns the chi-square statistic of counts against a uniform distribution
+func chiSquare(counts map[interface{}]int, total, categories int) float64 {
+	expected := float64(total) / float64(categories)
+	var sum float64
+	for _, c := range counts {
+		d := float64(c) - expected
+		sum += d * d / expected
+	}
+	// categories that were never hit
+	sum += float64(categories-len(counts)) * expected
+	return sum
+}
+
+func TestRandomSecure(t *testing.T) {
+	equal(t, 16, len(RandomBytes(16)))
+	unequal(t, RandomBytes(16), RandomBytes(16))
+	equal(t, 32, len(RandomToken(16)))
+
+	// The thresholds are far beyond the 99.99th percentile of the chi-square distribution,
+	// so a correct implementation practically never fails while a biased one does.
+	counts := make(map[interface{}]int)
+	for i := 0; i < 100000; i++ {
+		counts[RandomInt(0, 9)]++
+	}
+	gt(t, 45, chiSquare(counts, 100000, 10)) // 9 degrees of freedom
+
+	counts = make(map[interface{}]int)
+	for _, b := range RandomBytes(256000) {
+		counts[b]++
+	}
+	gt(t, 400, chiSquare(counts, 256000, 256)) // 255 degrees of freedom
+
+	counts = make(map[interface{}]int)
+	for _, c := range RandomString(62000, CharsAlphanumeric) {
+		counts[c]++
+	}
+	gt(t, 120, chiSquare(counts, 62000, 62)) // 61 degrees of freedom
+
+	tRandomString := RandomString(10, "简体")
+	equal(t, 10, MbStrLen(tRandomString))
+	equal(t, "", Trim(tRandomString, "简体"))
+
+	for i := 0; i < 100; i++ {
+		tRandomPassword := RandomPassword(8)
+		equal(t, 8, len(tRandomPassword))
+		for _, class := range []string{CharsLower, CharsUpper, CharsDigits, CharsSymbols} {
+			equal(t, true, strings.ContainsAny(tRandomPassword, class))
+		}
+	}
+	tRandomPassword := RandomPassword(2, "a", "b")
+	equal(t, true, tRandomPassword == "ab" || tRandomPassword == "ba")
+
+	tUniqid1 := Uniqid("", false)
+	tUniqid2 := Uniqid("", false)
+	equal(t, 13, len(tUniqid1))
+	equal(t, true, tUniqid2 > tUniqid1)
+	tUniqid3 := Uniqid("id-", true)
+	equal(t, 26, len(tUniqid3))
+	equal(t, "id-", tUniqid3[:3])
+	equal(t, byte('.'), tUniqid3[17])
+}
@@ -0,0 +1,257 @@
+package utils
+
+import (
+	"sort"
+	"strings"
+)
+
+// Sort flags, with the same values as PHP's SORT_* constants
+const (
+	SortRegular  = 0 // compare items normally (PHP 8 loose comparison)
+	SortNumeric  = 1 // compare items numerically
+	SortString   = 2 // compare items as strings
+	SortNatural  = 6 // compare items as strings using "natural ordering" like StrNatCmp
+	SortFlagCase = 8 // combined with SortString or SortNatural to compare case-insensitively
+)
+
+// Sort orders for ArrayMultisort, with the same values as PHP's SORT_ASC and SORT_DESC
+const (
+	SortDesc = 3
+	SortAsc  = 4
+)
+
+// sortCompare returns the comparison function for the given sort flags
+func sortCompare(flags int) func(a, b interface{}) int {
+	foldCase := flags&SortFlagCase != 0
+
+	switch flags &^ SortFlagCase {
+	case SortNumeric:
+		return func(a, b interface{}) int {
+			fa, fb := phpFloat(a), phpFloat(b)
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	case SortString:
+		if foldCase {
+			return func(a, b interface{}) int {
+				return strings.Compare(toLowerASCII(phpString(a)), toLowerASCII(phpString(b)))
+			}
+		}
+		return func(a, b interface{}) int {
+			return strings.Compare(phpString(a), phpString(b))
+		}
+	case SortNatural:
+		if foldCase {
+			return func(a, b interface{}) int {
+				return StrNatCaseCmp(phpString(a), phpString(b))
+			}
+		}
+		return func(a, b interface{}) int {
+			return StrNatCmp(phpString(a), phpString(b))
+		}
+	}
+
+	return phpCompare
+}
+
+// toLowerASCII lowercases ASCII letters only, like PHP's strcasecmp
+func toLowerASCII(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			b[i] = c - 'A' + 'a'
+		}
+	}
+	return string(b)
+}
+
+// sortSlice sorts s stably in place
+func sortSlice(s []interface{}, compare func(a, b interface{}) int, reverse bool) {
+	sort.SliceStable(s, func(i, j int) bool {
+		if reverse {
+			return compare(s[i], s[j]) > 0
+		}
+		return compare(s[i], s[j]) < 0
+	})
+}
+
+// Sort — Sort an array in ascending order
+// flags is one of SortRegular, SortNumeric, SortString or SortNatural, optionally combined with SortFlagCase.
+// Elements that compare equal keep their original order, as in PHP 8.
+func Sort(s []interface{}, flags int) {
+	sortSlice(s, sortCompare(flags), false)
+}
+
+// Rsort — Sort an array in descending order
+func Rsort(s []interface{}, flags int) {
+	sortSlice(s, sortCompare(flags), true)
+}
+
+// Usort — Sort an array by values using a user-defined comparison function
+// callback returns < 0, 0 or > 0 when a is less than, equal to or greater than b.
+func Usort(s []interface{}, callback func(a, b interface{}) int) {
+	sortSlice(s, callback, false)
+}
+
+// sortMap sorts the entries of m stably, comparing either keys or values
+func (m *OrderedMap) sortMap(compare func(a, b interface{}) int, byKey, reverse, keepKeys bool) {
+	keys := m.Keys()
+	sort.SliceStable(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if !byKey {
+			a, b = m.values[a], m.values[b]
+		}
+		if reverse {
+			return compare(a, b) > 0
+		}
+		return compare(a, b) < 0
+	})
+
+	if keepKeys {
+		m.keys = keys
+		return
+	}
+
+	values := m.values
+	m.keys, m.values = nil, nil
+	m.init()
+	for _, k := range keys {
+		m.Push(values[k])
+	}
+}
+
+// Sort — Sort the map by values in ascending order and re-index it from 0
+func (m *OrderedMap) Sort(flags int) {
+	m.sortMap(sortCompare(flags), false, false, false)
+}
+
+// Rsort — Sort the map by values in descending order and re-index it from 0
+func (m *OrderedMap) Rsort(flags int) {
+	m.sortMap(sortCompare(flags), false, true, false)
+}
+
+// Usort — Sort the map by values using a user-defined comparison function and re-index it from 0
+func (m *OrderedMap) Usort(callback func(a, b interface{}) int) {
+	m.sortMap(callback, false, false, false)
+}
+
+// Asort — Sort the map by values in ascending order and maintain index association
+func (m *OrderedMap) Asort(flags int) {
+	m.sortMap(sortCompare(flags), false, false, true)
+}
+
+// Arsort — Sort the map by values in descending order and maintain index association
+func (m *OrderedMap) Arsort(flags int) {
+	m.sortMap(sortCompare(flags), false, true, true)
+}
+
+// Uasort — Sort the map with a user-defined comparison function and maintain index association
+func (m *OrderedMap) Uasort(callback func(a, b interface{}) int) {
+	m.sortMap(callback, false, false, true)
+}
+
+// Ksort — Sort the map by keys in ascending order
+func (m *OrderedMap) Ksort(flags int) {
+	m.sortMap(sortCompare(flags), true, false, true)
+}
+
+// Krsort — Sort the map by keys in descending order
+func (m *OrderedMap) Krsort(flags int) {
+	m.sortMap(sortCompare(flags), true, true, true)
+}
+
+// Uksort — Sort the map by keys using a user-defined comparison function
+func (m *OrderedMap) Uksort(callback func(a, b interface{}) int) {
+	m.sortMap(callback, true, false, true)
+}
+
+// Natsort — Sort the map using a "natural order" algorithm and maintain index association
+func (m *OrderedMap) Natsort() {
+	m.Asort(SortNatural)
+}
+
+// Natcasesort — Sort the map using a case insensitive "natural order" algorithm and maintain index association
+func (m *OrderedMap) Natcasesort() {
+	m.Asort(SortNatural | SortFlagCase)
+}
+
+// ArrayMultisort — Sort multiple arrays at once
+// Each array may be followed by a sort order (SortAsc or SortDesc) and sort flags, in any order.
+// The first array is sorted, ties are broken by the following arrays, and every array is
+// reordered the same way in place. All arrays must have the same length.
+// Usage:
+// ArrayMultisort(data1, SortDesc, data2, SortAsc, SortString)
+func ArrayMultisort(args ...interface{}) {
+	type column struct {
+		data    []interface{}
+		reverse bool
+		compare func(a, b interface{}) int
+		order   bool
+		flags   bool
+	}
+
+	var columns []*column
+	for _, arg := range args {
+		switch v := arg.(type) {
+		case []interface{}:
+			if len(columns) > 0 && len(v) != len(columns[0].data) {
+				panic("array sizes are inconsistent")
+			}
+			columns = append(columns, &column{data: v, compare: phpCompare})
+		case int:
+			if len(columns) == 0 {
+				panic("args: an array must come before sort order or flags")
+			}
+
+			c := columns[len(columns)-1]
+			if v == SortAsc || v == SortDesc {
+				if c.order {
+					panic("args: sort order has already been specified")
+				}
+				c.order, c.reverse = true, v == SortDesc
+			} else {
+				if c.flags {
+					panic("args: sort flags have already been specified")
+				}
+				c.flags, c.compare = true, sortCompare(v)
+			}
+		default:
+			panic("args: expected []interface{} or sort order or flags")
+		}
+	}
+
+	if len(columns) == 0 {
+		return
+	}
+
+	perm := make([]int, len(columns[0].data))
+	for i := range perm {
+		perm[i] = i
+	}
+
+	sort.SliceStable(perm, func(i, j int) bool {
+		for _, c := range columns {
+			r := c.compare(c.data[perm[i]], c.data[perm[j]])
+			if c.reverse {
+				r = -r
+			}
+			if r != 0 {
+				return r < 0
+			}
+		}
+		return false
+	})
+
+	for _, c := range columns {
+		sorted := make([]interface{}, len(perm))
+		for i, p := range perm {
+			sorted[i] = c.data[p]
+		}
+		copy(c.data, sorted)
+	}
+}
@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"testing"
+)
+
+func TestSort(t *testing.T) {
+	natcmps := []struct {
+		a, b     string
+		expected int
+	}{
+		{"img12.png", "img10.png", 1},
+		{"img2.png", "img10.png", -1},
+		{"img2", "IMG2", 1},
+		{"a", "", 1},
+		{"", "", 0},
+		{"x01", "x1", -1},
+		{"0.5", "0.25", -1},
+		{"1.010", "1.01", 1},
+		{"  abc", "abc", 0},
+		{"abc1", "abc01", 1},
+		{"007", "7", 0},
+		{"a 1", "a  1", 0},
+		{"10", "9", 1},
+	}
+	for _, c := range natcmps {
+		equal(t, c.expected, StrNatCmp(c.a, c.b))
+		equal(t, -c.expected, StrNatCmp(c.b, c.a))
+	}
+	equal(t, 0, StrNatCaseCmp("IMG2", "img2"))
+	equal(t, -1, StrNatCaseCmp("IMG2", "img12"))
+
+	sorts := []struct {
+		flags            int
+		input, asc, desc []interface{}
+	}{
+		{SortRegular, []interface{}{10, 9, "8", 1.5, "1e1"}, []interface{}{1.5, "8", 9, 10, "1e1"}, []interface{}{10, "1e1", 9, "8", 1.5}},
+		{SortNumeric, []interface{}{"10", "9", "2abc", "abc"}, []interface{}{"abc", "2abc", "9", "10"}, []interface{}{"10", "9", "2abc", "abc"}},
+		{SortString, []interface{}{"10", 9, "b", "B", "a"}, []interface{}{"10", 9, "B", "a", "b"}, []interface{}{"b", "a", "B", 9, "10"}},
+		{SortString | SortFlagCase, []interface{}{"b", "B", "a", "_"}, []interface{}{"_", "a", "b", "B"}, []interface{}{"b", "B", "a", "_"}},
+		{SortNatural, []interface{}{"img12", "img10", "IMG2", "img1"}, []interface{}{"IMG2", "img1", "img10", "img12"}, []interface{}{"img12", "img10", "img1", "IMG2"}},
+		{SortNatural | SortFlagCase, []interface{}{"img12", "img10", "IMG2", "img1"}, []interface{}{"img1", "IMG2", "img10", "img12"}, []interface{}{"img12", "img10", "IMG2", "img1"}},
+	}
+	for _, c := range sorts {
+		asc := append([]interface{}(nil), c.input...)
+		Sort(asc, c.flags)
+		equal(t, c.asc, asc)
+
+		desc := append([]interface{}(nil), c.input...)
+		Rsort(desc, c.flags)
+		equal(t, c.desc, desc)
+	}
+
+	// stable: equal elements keep their order
+	type pair struct{ k, v int }
+	stable := []interface{}{pair{1, 0}, pair{0, 1}, pair{1, 2}, pair{0, 3}}
+	Usort(stable, func(a, b interface{}) int { return a.(pair).k - b.(pair).k })
+	equal(t, []interface{}{pair{0, 1}, pair{0, 3}, pair{1, 0}, pair{1, 2}}, stable)
+
+	fruits := func() *OrderedMap {
+		m := &OrderedMap{}
+		m.Set("d", "lemon")
+		m.Set("a", "orange")
+		m.Set("b", "banana")
+		m.Set("c", "apple")
+		return m
+	}
+
+	m := fruits()
+	m.Asort(SortRegular)
+	equal(t, []interface{}{"c", "b", "d", "a"}, m.Keys())
+	m.Arsort(SortRegular)
+	equal(t, []interface{}{"a", "d", "b", "c"}, m.Keys())
+	m.Ksort(SortRegular)
+	equal(t, []interface{}{"a", "b", "c", "d"}, m.Keys())
+	m.Krsort(SortRegular)
+	equal(t, []interface{}{"d", "c", "b", "a"}, m.Keys())
+	m.Uasort(func(a, b interface{}) int { return len(a.(string)) - len(b.(string)) })
+	equal(t, []interface{}{"d", "c", "b", "a"}, m.Keys())
+	m.Uksort(func(a, b interface{}) int { return StrNatCmp(b.(string), a.(string)) })
+	equal(t, []interface{}{"d", "c", "b", "a"}, m.Keys())
+
+	m = fruits()
+	m.Sort(SortString)
+	equal(t, []interface{}{0, 1, 2, 3}, m.Keys())
+	equal(t, []interface{}{"apple", "banana", "lemon", "orange"}, m.Values())
+	equal(t, 5, m.Push("pear"))
+	m.Rsort(SortString)
+	equal(t, []interface{}{"pear", "orange", "lemon", "banana", "apple"}, m.Values())
+	m.Usort(func(a, b interface{}) int { return len(a.(string)) - len(b.(string)) })
+	equal(t, []interface{}{"pear", "lemon", "apple", "orange", "banana"}, m.Values())
+
+	imgs := NewOrderedMap("img12.png", "img10.png", "IMG2.png", "img1.png")
+	imgs.Natsort()
+	equal(t, []interface{}{2, 3, 1, 0}, imgs.Keys())
+	imgs.Natcasesort()
+	equal(t, []interface{}{3, 2, 1, 0}, imgs.Keys())
+
+	mixed := &OrderedMap{}
+	mixed.Set(10, "a")
+	mixed.Set("9", "b")
+	mixed.Set("x", "c")
+	mixed.Set(-1, "d")
+	mixed.Ksort(SortRegular)
+	equal(t, []interface{}{-1, 9, 10, "x"}, mixed.Keys())
+
+	data1 := []interface{}{3, 1, 3, 2}
+	data2 := []interface{}{"a", "b", "c", "d"}
+	ArrayMultisort(data1, SortDesc, data2, SortAsc, SortString)
+	equal(t, []interface{}{3, 3, 2, 1}, data1)
+	equal(t, []interface{}{"a", "c", "d", "b"}, data2)
+
+	data3 := []interface{}{"10", 100, 100, 11, "a"}
+	data4 := []interface{}{1, 3, "2", 2, 1}
+	ArrayMultisort(data3, data4)
+	equal(t, []interface{}{"10", 11, 100, 100, "a"}, data3)
+	equal(t, []interface{}{1, 2, "2", 3, 1}, data4)
+}
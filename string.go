@@ -2,6 +2,7 @@ package utils
 
 import (
 	"bytes"
+	"cmp"
 	"crypto/md5"
 	"crypto/sha1"
 	"encoding/hex"
@@ -10,11 +11,9 @@ import (
 	"hash/crc32"
 	"html"
 	"io/ioutil"
-	"math/rand"
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
 	"unicode"
 	"unicode/utf8"
 )
@@ -592,16 +591,14 @@ func Strtr(haystack string, params ...interface{}) string {
 }
 
 // StrShuffle — Randomly shuffles a string
+// The characters are shuffled with the package random source, see SetRandom.
 func StrShuffle(str string) string {
 	runes := []rune(str)
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
-	s := make([]rune, len(runes))
-
-	for i, v := range r.Perm(len(runes)) {
-		s[i] = runes[v]
-	}
+	shuffle(getRandom(), len(runes), func(i, j int) {
+		runes[i], runes[j] = runes[j], runes[i]
+	})
 
-	return string(s)
+	return string(runes)
 }
 
 // Trim — Strip whitespace (or other characters) from the beginning and end of a string
@@ -992,3 +989,122 @@ func Soundex(str string) string {
 
 	return string(sd)
 }
+
+// StrNatCmp — String comparisons using a "natural order" algorithm
+// Returns < 0 if str1 is less than str2, > 0 if str1 is greater than str2, and 0 if they are equal.
+// StrNatCmp("img12.png", "img10.png") == 1
+func StrNatCmp(str1, str2 string) int {
+	return strNatCmp(str1, str2, false)
+}
+
+// StrNatCaseCmp — Case insensitive string comparisons using a "natural order" algorithm
+func StrNatCaseCmp(str1, str2 string) int {
+	return strNatCmp(str1, str2, true)
+}
+
+// strNatCmp is a port of PHP's strnatcmp_ex
+func strNatCmp(a, b string, caseInsensitive bool) int {
+	if len(a) == 0 || len(b) == 0 {
+		return cmp.Compare(len(a), len(b))
+	}
+
+	at := func(s string, i int) byte {
+		if i < len(s) {
+			return s[i]
+		}
+		return 0
+	}
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	isSpace := func(c byte) bool { return c == ' ' || (c >= '\t' && c <= '\r') }
+
+	// compareDigits compares two runs of digits. Left aligned runs (fractional parts or
+	// leading zeros) are decided by the first difference, right aligned runs by length first.
+	compareDigits := func(ap, bp *int, leftAligned bool) int {
+		bias := 0
+		for ; ; *ap, *bp = *ap+1, *bp+1 {
+			ca, cb := at(a, *ap), at(b, *bp)
+			switch {
+			case !isDigit(ca) && !isDigit(cb):
+				return bias
+			case !isDigit(ca):
+				return -1
+			case !isDigit(cb):
+				return 1
+			case ca != cb:
+				if leftAligned {
+					return cmp.Compare(ca, cb)
+				}
+				if bias == 0 {
+					bias = cmp.Compare(ca, cb)
+				}
+			}
+		}
+	}
+
+	ap, bp, leading := 0, 0, true
+	for {
+		ca, cb := at(a, ap), at(b, bp)
+
+		// skip over leading zeros
+		for leading && ca == '0' && ap+1 < len(a) && isDigit(a[ap+1]) {
+			ap++
+			ca = a[ap]
+		}
+		for leading && cb == '0' && bp+1 < len(b) && isDigit(b[bp+1]) {
+			bp++
+			cb = b[bp]
+		}
+		leading = false
+
+		// skip consecutive whitespace
+		for isSpace(ca) {
+			ap++
+			ca = at(a, ap)
+		}
+		for isSpace(cb) {
+			bp++
+			cb = at(b, bp)
+		}
+
+		// process run of digits
+		if isDigit(ca) && isDigit(cb) {
+			if result := compareDigits(&ap, &bp, ca == '0' || cb == '0'); result != 0 {
+				return result
+			}
+			switch {
+			case ap >= len(a) && bp >= len(b):
+				return 0
+			case ap >= len(a):
+				return -1
+			case bp >= len(b):
+				return 1
+			}
+			ca, cb = a[ap], b[bp]
+		}
+
+		if caseInsensitive {
+			ca, cb = toUpperASCII(ca), toUpperASCII(cb)
+		}
+		if ca != cb {
+			return cmp.Compare(ca, cb)
+		}
+
+		ap++
+		bp++
+		switch {
+		case ap >= len(a) && bp >= len(b):
+			return 0
+		case ap >= len(a):
+			return -1
+		case bp >= len(b):
+			return 1
+		}
+	}
+}
+
+func toUpperASCII(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
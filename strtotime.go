@@ -0,0 +1,1091 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// This file ports the scanner of timelib, the date library behind PHP's strtotime.
+// The input is split into tokens, each matched by the longest of the rules below;
+// every rule fills absolute fields, relative offsets or the timezone of a strToTime.
+
+const timeUnset = math.MinInt32
+
+// special relative types
+const (
+	specialWeekdayCount = iota + 1
+	specialDayOfWeekInMonth
+	specialLastDayOfWeekInMonth
+)
+
+type strToTime struct {
+	y, m, d, h, i, s, us int
+	haveTime, haveDate   bool
+	haveZone             int
+	loc                  *time.Location
+
+	rel struct {
+		y, m, d, h, i, s, us int
+
+		haveWeekday     bool
+		weekday         int
+		weekdayBehavior int
+
+		firstLastDayOf int // 1: first day of; 2: last day of
+		special        int
+		specialAmount  int
+	}
+}
+
+func (p *strToTime) setTime() error {
+	if p.haveTime {
+		return fmt.Errorf("double time specification")
+	}
+	p.haveTime = true
+	p.h, p.i, p.s, p.us = 0, 0, 0, 0
+	return nil
+}
+
+func (p *strToTime) unsetTime() {
+	p.haveTime = false
+	p.h, p.i, p.s, p.us = 0, 0, 0, 0
+}
+
+func (p *strToTime) setDate() error {
+	if p.haveDate {
+		return fmt.Errorf("double date specification")
+	}
+	p.haveDate = true
+	return nil
+}
+
+func (p *strToTime) setZone(c *dateCursor) error {
+	loc, err := parseTimezone(c.rest())
+	if err != nil {
+		return err
+	}
+
+	// a second timezone is ignored, like PHP does with a warning
+	if p.haveZone++; p.haveZone == 1 {
+		p.loc = loc
+	}
+	return nil
+}
+
+// setRelative applies amount of the unit under the cursor
+func (p *strToTime) setRelative(c *dateCursor, amount, behavior int, keepTime bool) error {
+	word := c.word()
+	unit, ok := relativeUnits[word]
+	if !ok {
+		return fmt.Errorf("unknown relative unit %q", word)
+	}
+
+	switch unit.field {
+	case 'u':
+		p.rel.us += amount * unit.multiplier
+	case 's':
+		p.rel.s += amount * unit.multiplier
+	case 'i':
+		p.rel.i += amount * unit.multiplier
+	case 'h':
+		p.rel.h += amount * unit.multiplier
+	case 'd':
+		p.rel.d += amount * unit.multiplier
+	case 'm':
+		p.rel.m += amount * unit.multiplier
+	case 'y':
+		p.rel.y += amount * unit.multiplier
+	case 'w':
+		p.rel.haveWeekday = true
+		if !keepTime {
+			p.unsetTime()
+		}
+		if amount > 0 {
+			p.rel.d += (amount - 1) * 7
+		} else {
+			p.rel.d += amount * 7
+		}
+		p.rel.weekday = unit.multiplier
+		p.rel.weekdayBehavior = behavior
+	case 'x':
+		if !keepTime {
+			p.unsetTime()
+		}
+		p.rel.special = specialWeekdayCount
+		p.rel.specialAmount = amount
+	}
+
+	return nil
+}
+
+type relativeUnit struct {
+	field      byte
+	multiplier int
+}
+
+var relativeUnits = map[string]relativeUnit{
+	"ms": {'u', 1000}, "msec": {'u', 1000}, "msecs": {'u', 1000}, "millisecond": {'u', 1000}, "milliseconds": {'u', 1000},
+	"µs": {'u', 1}, "usec": {'u', 1}, "usecs": {'u', 1}, "µsec": {'u', 1}, "µsecs": {'u', 1}, "microsecond": {'u', 1}, "microseconds": {'u', 1},
+	"sec": {'s', 1}, "secs": {'s', 1}, "second": {'s', 1}, "seconds": {'s', 1},
+	"min": {'i', 1}, "mins": {'i', 1}, "minute": {'i', 1}, "minutes": {'i', 1},
+	"hour": {'h', 1}, "hours": {'h', 1},
+	"day": {'d', 1}, "days": {'d', 1},
+	"week": {'d', 7}, "weeks": {'d', 7},
+	"fortnight": {'d', 14}, "fortnights": {'d', 14}, "forthnight": {'d', 14}, "forthnights": {'d', 14},
+	"month": {'m', 1}, "months": {'m', 1},
+	"year": {'y', 1}, "years": {'y', 1},
+
+	"monday": {'w', 1}, "mondays": {'w', 1}, "mon": {'w', 1},
+	"tuesday": {'w', 2}, "tuesdays": {'w', 2}, "tue": {'w', 2},
+	"wednesday": {'w', 3}, "wednesdays": {'w', 3}, "wed": {'w', 3},
+	"thursday": {'w', 4}, "thursdays": {'w', 4}, "thu": {'w', 4},
+	"friday": {'w', 5}, "fridays": {'w', 5}, "fri": {'w', 5},
+	"saturday": {'w', 6}, "saturdays": {'w', 6}, "sat": {'w', 6},
+	"sunday": {'w', 0}, "sundays": {'w', 0}, "sun": {'w', 0},
+
+	"weekday": {'x', 1}, "weekdays": {'x', 1},
+}
+
+// relativeTexts maps ordinal words to an amount and a weekday behavior
+var relativeTexts = map[string][2]int{
+	"last": {-1, 0}, "previous": {-1, 0}, "this": {0, 1},
+	"first": {1, 0}, "next": {1, 0}, "second": {2, 0}, "third": {3, 0}, "fourth": {4, 0},
+	"fifth": {5, 0}, "sixth": {6, 0}, "seventh": {7, 0}, "eight": {8, 0}, "eighth": {8, 0},
+	"ninth": {9, 0}, "tenth": {10, 0}, "eleventh": {11, 0}, "twelfth": {12, 0},
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "sept": 9, "oct": 10, "nov": 11, "dec": 12,
+	"january": 1, "february": 2, "march": 3, "april": 4, "june": 6, "july": 7,
+	"august": 8, "september": 9, "october": 10, "november": 11, "december": 12,
+	"i": 1, "ii": 2, "iii": 3, "iv": 4, "v": 5, "vi": 6,
+	"vii": 7, "viii": 8, "ix": 9, "x": 10, "xi": 11, "xii": 12,
+}
+
+// timezoneAbbreviations maps common abbreviations to their UTC offset in seconds
+var timezoneAbbreviations = map[string]int{
+	"ut": 0, "utc": 0, "gmt": 0, "z": 0, "wet": 0,
+	"west": 3600, "bst": 3600, "cet": 3600, "met": 3600, "wat": 3600,
+	"cest": 7200, "mest": 7200, "eet": 7200, "sast": 7200, "cat": 7200,
+	"eest": 10800, "msk": 10800, "eat": 10800,
+	"pkt": 18000, "wib": 25200, "ict": 25200,
+	"wita": 28800, "hkt": 28800, "sgt": 28800, "awst": 28800, "pht": 28800,
+	"jst": 32400, "kst": 32400, "wit": 32400,
+	"acst": 34200, "aest": 36000, "acdt": 37800, "aedt": 39600,
+	"nzst": 43200, "nzdt": 46800,
+	"nst": -12600, "ndt": -9000,
+	"ast": -14400, "adt": -10800,
+	"est": -18000, "edt": -14400,
+	"cst": -21600, "cdt": -18000,
+	"mst": -25200, "mdt": -21600,
+	"pst": -28800, "pdt": -25200,
+	"akst": -32400, "akdt": -28800,
+	"hst": -36000,
+}
+
+// parseTimezone parses an abbreviation, an UTC offset such as "+02:00" or "GMT-5", or an identifier
+// such as "Europe/Amsterdam"
+func parseTimezone(str string) (*time.Location, error) {
+	str = strings.Trim(strings.TrimSpace(str), "()")
+	lower := toLowerASCII(str)
+
+	if strings.HasPrefix(lower, "gmt") && len(lower) > 3 && (lower[3] == '+' || lower[3] == '-') {
+		lower, str = lower[3:], str[3:]
+	}
+
+	if lower != "" && (lower[0] == '+' || lower[0] == '-') {
+		sign, digits := 1, strings.Replace(lower[1:], ":", "", -1)
+		if lower[0] == '-' {
+			sign = -1
+		}
+
+		var h, m, s int
+		var err error
+		switch {
+		case strings.Contains(lower, ":"):
+			parts := strings.Split(lower[1:], ":")
+			h, err = strconv.Atoi(parts[0])
+			if err == nil && len(parts) > 1 {
+				m, err = strconv.Atoi(parts[1])
+			}
+			if err == nil && len(parts) > 2 {
+				s, err = strconv.Atoi(parts[2])
+			}
+		case len(digits) <= 2:
+			h, err = strconv.Atoi(digits)
+		case len(digits) == 3:
+			h, _ = strconv.Atoi(digits[:1])
+			m, err = strconv.Atoi(digits[1:])
+		case len(digits) == 4:
+			h, _ = strconv.Atoi(digits[:2])
+			m, err = strconv.Atoi(digits[2:])
+		case len(digits) == 6:
+			h, _ = strconv.Atoi(digits[:2])
+			m, _ = strconv.Atoi(digits[2:4])
+			s, err = strconv.Atoi(digits[4:])
+		default:
+			err = fmt.Errorf("invalid UTC offset %q", str)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		offset := sign * (h*3600 + m*60 + s)
+		if offset == 0 {
+			return time.UTC, nil
+		}
+		return time.FixedZone("", offset), nil
+	}
+
+	if offset, ok := timezoneAbbreviations[lower]; ok {
+		if offset == 0 && lower != "wet" {
+			return time.UTC, nil
+		}
+		return time.FixedZone(strings.ToUpper(lower), offset), nil
+	}
+
+	if strings.Contains(str, "/") {
+		if loc, err := time.LoadLocation(str); err == nil {
+			return loc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("the timezone %q could not be found", str)
+}
+
+// dateCursor reads the text matched by a rule
+type dateCursor struct {
+	s, orig string
+	pos     int
+}
+
+func (c *dateCursor) peek() byte {
+	if c.pos < len(c.s) {
+		return c.s[c.pos]
+	}
+	return 0
+}
+
+func (c *dateCursor) more() bool {
+	return c.pos < len(c.s)
+}
+
+func (c *dateCursor) rest() string {
+	return c.orig[c.pos:]
+}
+
+func (c *dateCursor) eatSpaces() {
+	for c.peek() == ' ' || c.peek() == '\t' {
+		c.pos++
+	}
+}
+
+// nr skips anything up to the next digit and reads at most maxLen digits
+func (c *dateCursor) nr(maxLen int) (int, int) {
+	for c.more() && (c.peek() < '0' || c.peek() > '9') {
+		c.pos++
+	}
+
+	start := c.pos
+	for c.more() && c.pos-start < maxLen && c.peek() >= '0' && c.peek() <= '9' {
+		c.pos++
+	}
+	if start == c.pos {
+		return timeUnset, 0
+	}
+
+	n, _ := strconv.Atoi(c.s[start:c.pos])
+	return n, c.pos - start
+}
+
+func (c *dateCursor) number(maxLen int) int {
+	n, _ := c.nr(maxLen)
+	return n
+}
+
+func (c *dateCursor) signedNr(maxLen int) int {
+	for c.more() && (c.peek() < '0' || c.peek() > '9') && c.peek() != '+' && c.peek() != '-' {
+		c.pos++
+	}
+
+	sign := 1
+	for c.peek() == '+' || c.peek() == '-' {
+		if c.peek() == '-' {
+			sign = -sign
+		}
+		c.pos++
+	}
+
+	return sign * c.number(maxLen)
+}
+
+// frac reads a fraction of a second such as ".123" as microseconds
+func (c *dateCursor) frac() int {
+	if c.peek() != '.' && c.peek() != ':' && c.peek() != ',' {
+		return 0
+	}
+	c.pos++
+
+	start := c.pos
+	for c.more() && c.peek() >= '0' && c.peek() <= '9' {
+		c.pos++
+	}
+
+	digits := c.s[start:c.pos]
+	if len(digits) > 6 {
+		digits = digits[:6]
+	}
+	us, _ := strconv.Atoi(digits + strings.Repeat("0", 6-len(digits)))
+	return us
+}
+
+// word reads the next run of letters
+func (c *dateCursor) word() string {
+	for c.more() && strings.IndexByte(" \t,.-/:;()", c.peek()) != -1 {
+		c.pos++
+	}
+
+	start := c.pos
+	for c.more() && (c.peek() >= 'a' && c.peek() <= 'z' || c.peek() >= 0x80) {
+		c.pos++
+	}
+
+	return c.s[start:c.pos]
+}
+
+func (c *dateCursor) month() int {
+	return monthNames[c.word()]
+}
+
+func (c *dateCursor) skipDaySuffix() {
+	if c.pos+2 <= len(c.s) {
+		switch c.s[c.pos : c.pos+2] {
+		case "st", "nd", "rd", "th":
+			c.pos += 2
+		}
+	}
+}
+
+// meridian returns the correction of h for the following am or pm
+func (c *dateCursor) meridian(h int) int {
+	for c.more() && c.peek() != 'a' && c.peek() != 'p' {
+		c.pos++
+	}
+
+	correction := 0
+	if c.peek() == 'a' {
+		if h == 12 {
+			correction = -12
+		}
+	} else if h != 12 {
+		correction = 12
+	}
+
+	c.pos++
+	for c.peek() == '.' || c.peek() == 'm' {
+		c.pos++
+	}
+
+	return correction
+}
+
+// processYear turns two-digit years into 1970-2069
+func processYear(y, length int) int {
+	if y == timeUnset || length >= 4 || y >= 100 {
+		return y
+	}
+	if y < 70 {
+		return y + 2000
+	}
+	return y + 1900
+}
+
+type dateRule struct {
+	re *regexp.Regexp
+	fn func(p *strToTime, c *dateCursor) error
+}
+
+func newDateRule(pattern string, fn func(p *strToTime, c *dateCursor) error) dateRule {
+	re := regexp.MustCompile(`^(?:` + pattern + `)`)
+	re.Longest()
+	return dateRule{re, fn}
+}
+
+const (
+	reSpace       = `[ \t]+`
+	reFrac        = `\.[0-9]+`
+	reHour24      = `(?:2[0-4]|[01]?[0-9])`
+	reHour24lz    = `(?:[01][0-9]|2[0-4])`
+	reHour12      = `(?:1[0-2]|0?[1-9])`
+	reMinute      = `[0-5]?[0-9]`
+	reMinutelz    = `[0-5][0-9]`
+	reSecond      = `(?:60|[0-5]?[0-9])`
+	reSecondlz    = `(?:60|[0-5][0-9])`
+	reMeridian    = `[ap]\.?m\.?(?:[ \t]|$)`
+	reTz          = `\(?[a-z]{1,6}\)?|[a-z]+(?:[_/-][a-z]+)+`
+	reTzCorrect   = `(?:gmt)?[+-](?:` + reHour24 + `:?(?:` + reMinute + `)?|` + reHour24lz + reMinutelz + reSecondlz + `)`
+	reMonth       = `(?:1[0-2]|0?[0-9])`
+	reDay         = `(?:3[01]|[0-2]?[0-9])(?:st|nd|rd|th)?`
+	reYear        = `[0-9]{1,4}`
+	reYear2       = `[0-9]{2}`
+	reYear4       = `[0-9]{4}`
+	reYear4Sign   = `[+-]?[0-9]{4}`
+	reMonthlz     = `(?:0[0-9]|1[0-2])`
+	reDaylz       = `(?:0[0-9]|[12][0-9]|3[01])`
+	reDayOfYear   = `(?:00[1-9]|0[1-9][0-9]|[12][0-9][0-9]|3[0-5][0-9]|36[0-6])`
+	reWeekOfYear  = `(?:0[1-9]|[1-4][0-9]|5[0-3])`
+	reDayFull     = `(?:sunday|monday|tuesday|wednesday|thursday|friday|saturday)`
+	reDayFulls    = `(?:sundays|mondays|tuesdays|wednesdays|thursdays|fridays|saturdays)`
+	reDayAbbr     = `(?:sun|mon|tue|wed|thu|fri|sat)`
+	reDayText     = `(?:` + reDayFulls + `|` + reDayFull + `|` + reDayAbbr + `|weekdays?)`
+	reMonthFull   = `(?:january|february|march|april|may|june|july|august|september|october|november|december)`
+	reMonthAbbr   = `(?:jan|feb|mar|apr|may|jun|jul|aug|sept?|oct|nov|dec)`
+	reMonthRoman  = `(?:xii|xi|x|ix|viii|vii|vi|v|iv|iii|ii|i)`
+	reMonthText   = `(?:` + reMonthFull + `|` + reMonthAbbr + `|` + reMonthRoman + `)`
+	reRelTextNum  = `(?:first|second|third|fourth|fifth|sixth|seventh|eighth|eight|ninth|tenth|eleventh|twelfth)`
+	reRelTextText = `(?:next|last|previous|this)`
+	reRelTextUnit = `(?:ms|µs|(?:msec|millisecond|µsec|microsecond|usec|sec|second|min|minute|hour|day|fortnight|forthnight|month|year)s?|weeks|` + reDayText + `)`
+
+	reTimeShort12 = reHour12 + `[:.]` + reMinute + `[ \t]*` + reMeridian
+	reTimeLong12  = reHour12 + `[:.]` + reMinute + `[:.]` + reSecond + `[ \t]*` + reMeridian
+	reTimeShort24 = `t?` + reHour24 + `[:.]` + reMinute
+	reTimeLong24  = `t?` + reHour24 + `[:.]` + reMinute + `[:.]` + reSecond
+	reIsoLong     = `t?` + reHour24 + `[:.]` + reMinute + `[:.]` + reSecond + reFrac
+	reDateNoYear  = reMonthText + `[ .\t-]*` + reDay + `(?:[,.stndrh\t ]+|$)`
+)
+
+func dateOnly(fn func(p *strToTime, c *dateCursor)) func(p *strToTime, c *dateCursor) error {
+	return func(p *strToTime, c *dateCursor) error {
+		if err := p.setDate(); err != nil {
+			return err
+		}
+		fn(p, c)
+		return nil
+	}
+}
+
+// readTime24 reads hours, minutes, optional seconds and fraction, and an optional timezone
+func readTime24(p *strToTime, c *dateCursor) error {
+	if err := p.setTime(); err != nil {
+		return err
+	}
+
+	p.h = c.number(2)
+	p.i = c.number(2)
+	if c.peek() == ':' || c.peek() == '.' {
+		p.s = c.number(2)
+		p.us = c.frac()
+	}
+
+	if c.eatSpaces(); c.more() {
+		return p.setZone(c)
+	}
+	return nil
+}
+
+// readTime12 reads hours, optional minutes and seconds followed by am or pm
+func readTime12(p *strToTime, c *dateCursor) error {
+	if err := p.setTime(); err != nil {
+		return err
+	}
+
+	p.h = c.number(2)
+	if c.peek() == ':' || c.peek() == '.' {
+		p.i = c.number(2)
+		if c.peek() == ':' || c.peek() == '.' {
+			p.s = c.number(2)
+		}
+	}
+	p.h += c.meridian(p.h)
+
+	return nil
+}
+
+// readDateTime reads year, month, day, hours, minutes, seconds, fraction and timezone in this order
+func readDateTime(p *strToTime, c *dateCursor) error {
+	if err := p.setTime(); err != nil {
+		return err
+	}
+	if err := p.setDate(); err != nil {
+		return err
+	}
+
+	p.y = c.number(4)
+	p.m = c.number(2)
+	p.d = c.number(2)
+	p.h = c.number(2)
+	p.i = c.number(2)
+	p.s = c.number(2)
+	p.us = c.frac()
+
+	if c.eatSpaces(); c.more() {
+		return p.setZone(c)
+	}
+	return nil
+}
+
+// readDateNoYear reads a month name followed by a day
+func readDateNoYear(p *strToTime, c *dateCursor) {
+	p.m = c.month()
+	p.d = c.number(2)
+	c.skipDaySuffix()
+}
+
+var dateRules []dateRule
+
+func init() {
+	dateRules = []dateRule{
+		newDateRule(`yesterday`, func(p *strToTime, c *dateCursor) error {
+			p.unsetTime()
+			p.rel.d = -1
+			return nil
+		}),
+		newDateRule(`now`, func(p *strToTime, c *dateCursor) error {
+			return nil
+		}),
+		newDateRule(`noon`, func(p *strToTime, c *dateCursor) error {
+			p.unsetTime()
+			if err := p.setTime(); err != nil {
+				return err
+			}
+			p.h = 12
+			return nil
+		}),
+		newDateRule(`midnight|today`, func(p *strToTime, c *dateCursor) error {
+			p.unsetTime()
+			return nil
+		}),
+		newDateRule(`tomorrow`, func(p *strToTime, c *dateCursor) error {
+			p.unsetTime()
+			p.rel.d = 1
+			return nil
+		}),
+
+		// @1126396800 and @1126396800.123
+		newDateRule(`@-?[0-9]+(?:\.[0-9]{0,6})?`, func(p *strToTime, c *dateCursor) error {
+			p.haveDate, p.haveTime = false, false
+			if err := p.setDate(); err != nil {
+				return err
+			}
+			if err := p.setTime(); err != nil {
+				return err
+			}
+
+			c.pos++
+			sign := 1
+			if c.peek() == '-' {
+				sign = -1
+			}
+			p.y, p.m, p.d = 1970, 1, 1
+			p.rel.s += sign * c.number(19)
+			p.rel.us += sign * c.frac()
+			p.haveZone++
+			p.loc = time.UTC
+			return nil
+		}),
+
+		newDateRule(`first day of|last day of`, func(p *strToTime, c *dateCursor) error {
+			if c.peek() == 'f' {
+				p.rel.firstLastDayOf = 1
+			} else {
+				p.rel.firstLastDayOf = 2
+			}
+			return nil
+		}),
+
+		// back of 7pm is 19:15, front of 7pm is 18:45
+		newDateRule(`(?:back|front) of `+reHour24+`(?:[ \t]*`+reMeridian+`)?`, func(p *strToTime, c *dateCursor) error {
+			p.unsetTime()
+			if err := p.setTime(); err != nil {
+				return err
+			}
+
+			back := c.peek() == 'b'
+			p.h, p.i = c.number(2), 15
+			if !back {
+				p.h, p.i = p.h-1, 45
+			}
+			if c.eatSpaces(); c.more() {
+				p.h += c.meridian(p.h)
+			}
+			return nil
+		}),
+
+		// first monday of, last friday of
+		newDateRule(`(?:`+reRelTextNum+`|`+reRelTextText+`)`+reSpace+`(?:`+reDayFulls+`|`+reDayFull+`|`+reDayAbbr+`)`+reSpace+`of`, func(p *strToTime, c *dateCursor) error {
+			text := relativeTexts[c.word()]
+			c.eatSpaces()
+			if text[0] > 0 {
+				p.rel.special = specialDayOfWeekInMonth
+				return p.setRelative(c, text[0], 1, false)
+			}
+			p.rel.special = specialLastDayOfWeekInMonth
+			return p.setRelative(c, text[0], text[1], false)
+		}),
+
+		newDateRule(reHour12+`[ \t]*`+reMeridian+`|`+reTimeShort12+`|`+reTimeLong12, readTime12),
+
+		// mssql: 10:00:00.000AM
+		newDateRule(reHour12+`:`+reMinutelz+`:`+reSecondlz+`[:.][0-9]+`+reMeridian, func(p *strToTime, c *dateCursor) error {
+			if err := p.setTime(); err != nil {
+				return err
+			}
+
+			p.h = c.number(2)
+			p.i = c.number(2)
+			p.s = c.number(2)
+			p.us = c.frac()
+			p.h += c.meridian(p.h)
+			return nil
+		}),
+
+		newDateRule(`t`+reHour24+`|`+reTimeShort24+`|`+reTimeLong24+`|`+reIsoLong, readTime24),
+
+		// hhmm, or the year when a time is already known
+		newDateRule(`t?`+reHour24lz+reMinutelz, func(p *strToTime, c *dateCursor) error {
+			if p.haveTime {
+				p.y = c.number(4)
+				return nil
+			}
+			if err := p.setTime(); err != nil {
+				return err
+			}
+			p.h = c.number(2)
+			p.i = c.number(2)
+			return nil
+		}),
+
+		newDateRule(`t?`+reHour24lz+reMinutelz+reSecondlz, func(p *strToTime, c *dateCursor) error {
+			if err := p.setTime(); err != nil {
+				return err
+			}
+			p.h = c.number(2)
+			p.i = c.number(2)
+			p.s = c.number(2)
+			return nil
+		}),
+
+		// american: 1/2 and 1/2/2006
+		newDateRule(reMonth+`/`+reDay+`(?:/`+reYear+`)?`, dateOnly(func(p *strToTime, c *dateCursor) {
+			p.m = c.number(2)
+			p.d = c.number(2)
+			c.skipDaySuffix()
+			if c.peek() == '/' {
+				y, length := c.nr(4)
+				p.y = processYear(y, length)
+			}
+		})),
+
+		// iso8601: 2006-01-02, 2006/01/02 and 2006/1/2
+		newDateRule(reYear4Sign+`-`+reMonthlz+`-`+reDaylz+`|`+reYear4+`/`+reMonthlz+`/`+reDaylz+`/?|`+reYear4+`/`+reMonth+`/`+reDay, dateOnly(func(p *strToTime, c *dateCursor) {
+			sign := 1
+			if c.peek() == '-' {
+				sign = -1
+			}
+			p.y = sign * c.number(4)
+			p.m = c.number(2)
+			p.d = c.number(2)
+		})),
+
+		// 06-01-02
+		newDateRule(reYear2+`-`+reMonthlz+`-`+reDaylz, dateOnly(func(p *strToTime, c *dateCursor) {
+			y, length := c.nr(4)
+			p.y = processYear(y, length)
+			p.m = c.number(2)
+			p.d = c.number(2)
+		})),
+
+		// gnu: 2006-01 and 6-1-2
+		newDateRule(reYear4+`-`+reMonth, dateOnly(func(p *strToTime, c *dateCursor) {
+			y, length := c.nr(4)
+			p.y = processYear(y, length)
+			p.m = c.number(2)
+			p.d = 1
+		})),
+		newDateRule(reYear+`-`+reMonth+`-`+reDay, dateOnly(func(p *strToTime, c *dateCursor) {
+			y, length := c.nr(4)
+			p.y = processYear(y, length)
+			p.m = c.number(2)
+			p.d = c.number(2)
+		})),
+
+		// 2 January 2006, 2-jan-2006
+		newDateRule(reDay+`[ \t.-]*`+reMonthText+`[ \t.-]*`+reYear, dateOnly(func(p *strToTime, c *dateCursor) {
+			p.d = c.number(2)
+			c.skipDaySuffix()
+			p.m = c.month()
+			y, length := c.nr(4)
+			p.y = processYear(y, length)
+		})),
+
+		// pointed: 02.01.2006 and 02.01.06
+		newDateRule(reDay+`[.\t-]`+reMonth+`[.-]`+reYear4, dateOnly(func(p *strToTime, c *dateCursor) {
+			p.d = c.number(2)
+			p.m = c.number(2)
+			p.y = c.number(4)
+		})),
+		newDateRule(reDay+`[.\t]`+reMonth+`\.`+reYear2, dateOnly(func(p *strToTime, c *dateCursor) {
+			p.d = c.number(2)
+			p.m = c.number(2)
+			y, length := c.nr(2)
+			p.y = processYear(y, length)
+		})),
+
+		// January 2006
+		newDateRule(reMonthText+`[ .\t-]*`+reYear4, dateOnly(func(p *strToTime, c *dateCursor) {
+			p.m = c.month()
+			p.y = c.number(4)
+			p.d = 1
+		})),
+		// 2006 January
+		newDateRule(reYear4+`[ .\t-]*`+reMonthText, dateOnly(func(p *strToTime, c *dateCursor) {
+			p.y = c.number(4)
+			p.m = c.month()
+			p.d = 1
+		})),
+
+		// January 2nd, 2006 and January 2
+		newDateRule(reMonthText+`[ .\t-]*`+reDay+`[,.stndrh\t ]*`+reYear+`|`+reDateNoYear, dateOnly(func(p *strToTime, c *dateCursor) {
+			readDateNoYear(p, c)
+			y, length := c.nr(4)
+			p.y = processYear(y, length)
+		})),
+		// 2 January
+		newDateRule(reDay+`[ .\t-]*`+reMonthText, dateOnly(func(p *strToTime, c *dateCursor) {
+			p.d = c.number(2)
+			c.skipDaySuffix()
+			p.m = c.month()
+		})),
+
+		// January 2 15:04, which would otherwise be read as the year 15
+		newDateRule(reDateNoYear+`(?:`+reTimeShort12+`|`+reTimeLong12+`)`, func(p *strToTime, c *dateCursor) error {
+			if err := p.setDate(); err != nil {
+				return err
+			}
+			readDateNoYear(p, c)
+			return readTime12(p, c)
+		}),
+		newDateRule(reDateNoYear+`(?:`+reTimeShort24+`|`+reTimeLong24+`)(?:[ \t]*(?:`+reTzCorrect+`|`+reTz+`))?`, func(p *strToTime, c *dateCursor) error {
+			if err := p.setDate(); err != nil {
+				return err
+			}
+			readDateNoYear(p, c)
+			return readTime24(p, c)
+		}),
+
+		// 20060102
+		newDateRule(reYear4+reMonthlz+reDaylz, dateOnly(func(p *strToTime, c *dateCursor) {
+			p.y = c.number(4)
+			p.m = c.number(2)
+			p.d = c.number(2)
+		})),
+
+		// xmlrpc: 20060102T15:04:05 and 20060102t150405
+		newDateRule(reYear4+reMonthlz+reDaylz+`t`+reHour24+`:?`+reMinutelz+`:?`+reSecondlz, readDateTime),
+		// soap: 2006-01-02T15:04:05.000000-07:00
+		newDateRule(reYear4+`-`+reMonthlz+`-`+reDaylz+`t`+reHour24lz+`:`+reMinutelz+`:`+reSecondlz+reFrac+`(?:`+reTzCorrect+`)?`, readDateTime),
+		// wddx: 2006-1-2T15:04:05
+		newDateRule(reYear4+`-`+reMonth+`-`+reDay+`t`+reHour24+`:`+reMinute+`:`+reSecond, readDateTime),
+		// exif: 2006:01:02 15:04:05
+		newDateRule(reYear4+`:`+reMonthlz+`:`+reDaylz+` `+reHour24lz+`:`+reMinutelz+`:`+reSecondlz, readDateTime),
+
+		// day of year: 2006.002
+		newDateRule(reYear4+`\.?`+reDayOfYear, dateOnly(func(p *strToTime, c *dateCursor) {
+			y, length := c.nr(4)
+			p.y = processYear(y, length)
+			p.d = c.number(3)
+			p.m = 1
+		})),
+
+		// iso week: 2006W01, 2006-W01-1
+		newDateRule(reYear4+`-?w`+reWeekOfYear+`(?:-?[0-7])?`, dateOnly(func(p *strToTime, c *dateCursor) {
+			p.y = c.number(4)
+			w := c.number(2)
+			d := 1
+			if c.more() {
+				d = c.number(1)
+			}
+
+			dow := int(time.Date(p.y, 1, 1, 0, 0, 0, 0, time.UTC).Weekday())
+			if dow > 4 {
+				dow -= 7
+			}
+			p.m, p.d = 1, 1
+			p.rel.d += -dow + (w-1)*7 + d
+		})),
+
+		// pgsql: jan-02-2006 and 2006-jan-02
+		newDateRule(reMonthAbbr+`-`+reDaylz+`-`+reYear, dateOnly(func(p *strToTime, c *dateCursor) {
+			p.m = c.month()
+			p.d = c.number(2)
+			y, length := c.nr(4)
+			p.y = processYear(y, length)
+		})),
+		newDateRule(reYear+`-`+reMonthAbbr+`-`+reDaylz, dateOnly(func(p *strToTime, c *dateCursor) {
+			y, length := c.nr(4)
+			p.y = processYear(y, length)
+			p.m = c.month()
+			p.d = c.number(2)
+		})),
+
+		// common log format: 10/Oct/2000:13:55:36 -0700
+		newDateRule(reDay+`/`+reMonthAbbr+`/`+reYear4+`:`+reHour24lz+`:`+reMinutelz+`:`+reSecondlz+reSpace+reTzCorrect, func(p *strToTime, c *dateCursor) error {
+			if err := p.setTime(); err != nil {
+				return err
+			}
+			if err := p.setDate(); err != nil {
+				return err
+			}
+
+			p.d = c.number(2)
+			p.m = c.month()
+			p.y = c.number(4)
+			p.h = c.number(2)
+			p.i = c.number(2)
+			p.s = c.number(2)
+			c.eatSpaces()
+			return p.setZone(c)
+		}),
+
+		newDateRule(reYear4, func(p *strToTime, c *dateCursor) error {
+			p.y = c.number(4)
+			return nil
+		}),
+
+		newDateRule(`ago`, func(p *strToTime, c *dateCursor) error {
+			r := &p.rel
+			r.y, r.m, r.d, r.h, r.i, r.s, r.us = -r.y, -r.m, -r.d, -r.h, -r.i, -r.s, -r.us
+			if r.haveWeekday {
+				if r.weekday = -r.weekday; r.weekday == 0 {
+					r.weekday = -7
+				}
+			}
+			if r.special == specialWeekdayCount {
+				r.specialAmount = -r.specialAmount
+			}
+			return nil
+		}),
+
+		// monday, mon
+		newDateRule(reDayFull+`|`+reDayAbbr, func(p *strToTime, c *dateCursor) error {
+			p.unsetTime()
+			p.rel.haveWeekday = true
+			p.rel.weekday = relativeUnits[c.word()].multiplier
+			if p.rel.weekdayBehavior != 2 {
+				p.rel.weekdayBehavior = 1
+			}
+			return nil
+		}),
+
+		// next week, monday of this week
+		newDateRule(reRelTextText+reSpace+`week`, func(p *strToTime, c *dateCursor) error {
+			text := relativeTexts[c.word()]
+			c.eatSpaces()
+			if err := p.setRelative(c, text[0], text[1], false); err != nil {
+				return err
+			}
+
+			p.rel.weekdayBehavior = 2
+			if !p.rel.haveWeekday {
+				p.rel.haveWeekday = true
+				p.rel.weekday = 1
+			}
+			return nil
+		}),
+
+		// next month, last year, first day, third monday
+		newDateRule(`(?:`+reRelTextNum+`|`+reRelTextText+`)`+reSpace+reRelTextUnit, func(p *strToTime, c *dateCursor) error {
+			text := relativeTexts[c.word()]
+			c.eatSpaces()
+			return p.setRelative(c, text[0], text[1], false)
+		}),
+
+		newDateRule(reMonthFull+`|`+reMonthAbbr, dateOnly(func(p *strToTime, c *dateCursor) {
+			p.m = c.month()
+		})),
+
+		newDateRule(reTzCorrect+`|`+reTz, func(p *strToTime, c *dateCursor) error {
+			return p.setZone(c)
+		}),
+
+		// +1 week, -2 days, 3 hours
+		newDateRule(`[+-]*[ \t]*[0-9]{1,13}[ \t]*(?:`+reRelTextUnit+`|week)`, func(p *strToTime, c *dateCursor) error {
+			amount := c.signedNr(13)
+			c.eatSpaces()
+			return p.setRelative(c, amount, 0, true)
+		}),
+
+		newDateRule(`[ .,\t\n]`, func(p *strToTime, c *dateCursor) error {
+			return nil
+		}),
+	}
+}
+
+// parse runs the scanner over str
+func (p *strToTime) parse(str string) error {
+	p.y, p.m, p.d, p.h, p.i, p.s, p.us = timeUnset, timeUnset, timeUnset, timeUnset, timeUnset, timeUnset, timeUnset
+
+	lower := toLowerASCII(str)
+	for pos := 0; pos < len(lower); {
+		best, bestLen := -1, 0
+		for i, rule := range dateRules {
+			if loc := rule.re.FindStringIndex(lower[pos:]); loc != nil && loc[1] > bestLen {
+				best, bestLen = i, loc[1]
+			}
+		}
+		if best == -1 {
+			return fmt.Errorf("unexpected character %q at position %d", str[pos], pos)
+		}
+
+		c := &dateCursor{s: lower[pos : pos+bestLen], orig: str[pos : pos+bestLen]}
+		if err := dateRules[best].fn(p, c); err != nil {
+			return fmt.Errorf("%v at position %d (%s)", err, pos, strings.TrimSpace(c.orig))
+		}
+		pos += bestLen
+	}
+
+	return nil
+}
+
+// resolve computes the time described by the parsed fields relative to base
+func (p *strToTime) resolve(base time.Time) time.Time {
+	loc := base.Location()
+	if p.loc != nil {
+		loc = p.loc
+	}
+	now := base.In(loc)
+
+	if p.haveDate && !p.haveTime {
+		p.h, p.i, p.s, p.us = 0, 0, 0, 0
+	}
+	if p.us == timeUnset {
+		p.us = now.Nanosecond() / 1000
+		if p.y != timeUnset || p.m != timeUnset || p.d != timeUnset || p.h != timeUnset || p.i != timeUnset || p.s != timeUnset {
+			p.us = 0
+		}
+	}
+
+	fill := func(v *int, def int) {
+		if *v == timeUnset {
+			*v = def
+		}
+	}
+	fill(&p.y, now.Year())
+	fill(&p.m, int(now.Month()))
+	fill(&p.d, now.Day())
+	fill(&p.h, now.Hour())
+	fill(&p.i, now.Minute())
+	fill(&p.s, now.Second())
+
+	r := &p.rel
+	y, m, d := p.y, p.m, p.d
+	normalize := func() {
+		t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+		y, m, d = t.Year(), int(t.Month()), t.Day()
+	}
+
+	switch r.special {
+	case specialDayOfWeekInMonth:
+		d, m, r.m = 1, m+r.m, 0
+	case specialLastDayOfWeekInMonth:
+		d, m, r.m = 1, m+r.m+1, 0
+	}
+	normalize()
+
+	if r.haveWeekday {
+		dow := int(time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC).Weekday())
+		if r.weekdayBehavior == 2 {
+			weekday := r.weekday
+			// weeks start on monday, so sunday is the last day of the week
+			if dow == 0 && weekday != 0 {
+				weekday -= 7
+			}
+			if weekday == 0 && dow != 0 {
+				weekday = 7
+			}
+			d += weekday - dow
+		} else {
+			diff := r.weekday - dow
+			if (r.d < 0 && diff < 0) || (r.d >= 0 && diff <= -r.weekdayBehavior) {
+				diff += 7
+			}
+			if r.weekday >= 0 {
+				d += diff
+			} else {
+				d -= 7 - (-r.weekday - dow)
+			}
+		}
+		normalize()
+	}
+
+	y, m, d = y+r.y, m+r.m, d+r.d
+	switch r.firstLastDayOf {
+	case 1:
+		d = 1
+	case 2:
+		d, m = 0, m+1
+	}
+	normalize()
+
+	if r.special == specialWeekdayCount && r.specialAmount != 0 {
+		step, count := 1, r.specialAmount
+		if count < 0 {
+			step, count = -1, -count
+		}
+		t := time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
+		for count > 0 {
+			t = t.AddDate(0, 0, step)
+			if t.Weekday() != time.Saturday && t.Weekday() != time.Sunday {
+				count--
+			}
+		}
+		y, m, d = t.Year(), int(t.Month()), t.Day()
+	}
+
+	t := time.Date(y, time.Month(m), d, p.h, p.i, p.s, p.us*1000, loc)
+
+	// hours, minutes and seconds are elapsed time, so they are not affected by DST changes
+	secs := int64(r.h)*3600 + int64(r.i)*60 + int64(r.s)
+	if secs != 0 || r.us != 0 {
+		t = time.Unix(t.Unix()+secs, int64(t.Nanosecond())+int64(r.us)*1000).In(loc)
+	}
+
+	return t
+}
+
+// ParseStrToTime — Parse about any English textual datetime description relative to base
+// It implements the grammar of PHP's strtotime: absolute dates and times ("2006-01-02 15:04:05",
+// "Mon, 02 Jan 2006 15:04:05 -0700", "Jan 2 2006", "1/2/2006", "@1136214245"), relative
+// expressions ("+1 week 2 days", "next monday", "last day of next month", "tomorrow noon",
+// "3 days ago") and timezones ("UTC", "EST", "+02:00", "Europe/Amsterdam").
+// The result is in the timezone given in str, otherwise in the location of base.
+// An empty or blank str is an error, as strtotime("") returns false.
+func ParseStrToTime(str string, base time.Time) (time.Time, error) {
+	if strings.TrimSpace(str) == "" {
+		return time.Time{}, fmt.Errorf("strtotime: empty string")
+	}
+
+	p := &strToTime{}
+	if err := p.parse(str); err != nil {
+		return time.Time{}, fmt.Errorf("strtotime: %v", err)
+	}
+
+	return p.resolve(base), nil
+}
+
+// StrToTimeFrom — Parse about any English textual datetime description into a Unix timestamp
+// Relative expressions are evaluated against baseTimestamp in the package default timezone.
+// StrToTimeFrom("+1 day", 1524799394) == 1524885794
+func StrToTimeFrom(str string, baseTimestamp int64) (int64, error) {
+	t, err := ParseStrToTime(str, time.Unix(baseTimestamp, 0).In(getLocation()))
+	if err != nil {
+		return 0, err
+	}
+
+	return t.Unix(), nil
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStrToTimeParser(t *testing.T) {
+	// Wednesday
+	base := time.Date(2016, 1, 6, 10, 20, 30, 500000000, time.UTC)
+	layout := "2006-01-02 15:04:05.000000 -0700"
+
+	cases := []struct {
+		str, expected string
+	}{
+		// keywords
+		{"now", "2016-01-06 10:20:30.500000 +0000"},
+		{"today", "2016-01-06 00:00:00.000000 +0000"},
+		{"midnight", "2016-01-06 00:00:00.000000 +0000"},
+		{"noon", "2016-01-06 12:00:00.000000 +0000"},
+		{"tomorrow", "2016-01-07 00:00:00.000000 +0000"},
+		{"yesterday noon", "2016-01-05 12:00:00.000000 +0000"},
+		{"noon tomorrow", "2016-01-07 00:00:00.000000 +0000"},
+		{"tomorrow 10am", "2016-01-07 10:00:00.000000 +0000"},
+		{"yesterday 14:00", "2016-01-05 14:00:00.000000 +0000"},
+		{"back of 7pm", "2016-01-06 19:15:00.000000 +0000"},
+		{"front of 7pm", "2016-01-06 18:45:00.000000 +0000"},
+
+		// relative offsets
+		{"+1 day", "2016-01-07 10:20:30.500000 +0000"},
+		{"+1 week 2 days", "2016-01-15 10:20:30.500000 +0000"},
+		{"-1 week 2 days", "2016-01-01 10:20:30.500000 +0000"},
+		{"3 days ago", "2016-01-03 10:20:30.500000 +0000"},
+		{"1 hour ago", "2016-01-06 09:20:30.500000 +0000"},
+		{"+90 minutes", "2016-01-06 11:50:30.500000 +0000"},
+		{"+1500 ms", "2016-01-06 10:20:32.000000 +0000"},
+		{"+2 fortnights", "2016-02-03 10:20:30.500000 +0000"},
+		{"+1 month", "2016-02-06 10:20:30.500000 +0000"},
+		{"+1 year -2 months", "2016-11-06 10:20:30.500000 +0000"},
+		{"next year", "2017-01-06 10:20:30.500000 +0000"},
+		{"first day", "2016-01-07 10:20:30.500000 +0000"},
+		{"2016-01-31 +1 month", "2016-03-02 00:00:00.000000 +0000"},
+		{"+3 weekdays", "2016-01-11 10:20:30.500000 +0000"},
+		{"-3 weekdays", "2016-01-01 10:20:30.500000 +0000"},
+
+		// weekdays
+		{"wednesday", "2016-01-06 00:00:00.000000 +0000"},
+		{"sat", "2016-01-09 00:00:00.000000 +0000"},
+		{"this wednesday", "2016-01-06 00:00:00.000000 +0000"},
+		{"next monday", "2016-01-11 00:00:00.000000 +0000"},
+		{"last monday", "2016-01-04 00:00:00.000000 +0000"},
+		{"next wednesday", "2016-01-13 00:00:00.000000 +0000"},
+		{"last wednesday", "2015-12-30 00:00:00.000000 +0000"},
+		{"monday next week", "2016-01-11 00:00:00.000000 +0000"},
+		{"sunday this week", "2016-01-10 00:00:00.000000 +0000"},
+		{"this week", "2016-01-04 10:20:30.500000 +0000"},
+		{"next week", "2016-01-11 10:20:30.500000 +0000"},
+		{"last week", "2015-12-28 10:20:30.500000 +0000"},
+
+		// first and last day of
+		{"first day of next month", "2016-02-01 10:20:30.500000 +0000"},
+		{"last day of next month", "2016-02-29 10:20:30.500000 +0000"},
+		{"last day of february", "2016-02-29 00:00:00.000000 +0000"},
+		{"midnight first day of next month", "2016-02-01 00:00:00.000000 +0000"},
+		{"last day of 2016-01-31 +1 month", "2016-02-29 00:00:00.000000 +0000"},
+		{"first monday of january 2016", "2016-01-04 00:00:00.000000 +0000"},
+		{"second tuesday of march 2016", "2016-03-08 00:00:00.000000 +0000"},
+		{"last friday of next month", "2016-02-26 00:00:00.000000 +0000"},
+
+		// dates
+		{"2016-01-02", "2016-01-02 00:00:00.000000 +0000"},
+		{"2016/01/02", "2016-01-02 00:00:00.000000 +0000"},
+		{"06-01-02", "2006-01-02 00:00:00.000000 +0000"},
+		{"1/2/2016", "2016-01-02 00:00:00.000000 +0000"},
+		{"1/2", "2016-01-02 00:00:00.000000 +0000"},
+		{"02.01.2016", "2016-01-02 00:00:00.000000 +0000"},
+		{"20160102", "2016-01-02 00:00:00.000000 +0000"},
+		{"Jan 2 2016", "2016-01-02 00:00:00.000000 +0000"},
+		{"January 2nd, 2016", "2016-01-02 00:00:00.000000 +0000"},
+		{"2 Jan 2016", "2016-01-02 00:00:00.000000 +0000"},
+		{"2-Jan-2016", "2016-01-02 00:00:00.000000 +0000"},
+		{"January 2016", "2016-01-01 00:00:00.000000 +0000"},
+		{"2016 January", "2016-01-01 00:00:00.000000 +0000"},
+		{"Jan-02-2016", "2016-01-02 00:00:00.000000 +0000"},
+		{"2016W05", "2016-02-01 00:00:00.000000 +0000"},
+		{"2016-W05-3", "2016-02-03 00:00:00.000000 +0000"},
+		{"2016.032", "2016-02-01 00:00:00.000000 +0000"},
+		{"1960", "1960-01-06 10:20:30.000000 +0000"},
+
+		// times
+		{"3pm", "2016-01-06 15:00:00.000000 +0000"},
+		{"3:30 pm", "2016-01-06 15:30:00.000000 +0000"},
+		{"3:30:15 a.m.", "2016-01-06 03:30:15.000000 +0000"},
+		{"12am", "2016-01-06 00:00:00.000000 +0000"},
+		{"12pm", "2016-01-06 12:00:00.000000 +0000"},
+		{"15:04", "2016-01-06 15:04:00.000000 +0000"},
+		{"15:04:05.5", "2016-01-06 15:04:05.500000 +0000"},
+		{"t150405", "2016-01-06 15:04:05.000000 +0000"},
+		{"2016", "2016-01-06 20:16:00.000000 +0000"},
+
+		// dates with times
+		{"2016-01-02 15:04:05", "2016-01-02 15:04:05.000000 +0000"},
+		{"2016-01-02 noon", "2016-01-02 12:00:00.000000 +0000"},
+		{"Jan 2 15:04", "2016-01-02 15:04:00.000000 +0000"},
+		{"2016-01-02T15:04:05Z", "2016-01-02 15:04:05.000000 +0000"},
+		{"2016-01-02T15:04:05+07:00", "2016-01-02 15:04:05.000000 +0700"},
+		{"2016-01-02T15:04:05.123456-0200", "2016-01-02 15:04:05.123456 -0200"},
+		{"2016-01-02 15:04:05 +02:00", "2016-01-02 15:04:05.000000 +0200"},
+		{"20160102T150405", "2016-01-02 15:04:05.000000 +0000"},
+		{"2016:01:02 15:04:05", "2016-01-02 15:04:05.000000 +0000"},
+		{"Mon, 02 Jan 2006 15:04:05 -0700", "2006-01-02 15:04:05.000000 -0700"},
+		{"Mon, 02 Jan 2006 15:04:05 GMT", "2006-01-02 15:04:05.000000 +0000"},
+		{"10/Oct/2000:13:55:36 -0700", "2000-10-10 13:55:36.000000 -0700"},
+		{"@1136214245", "2006-01-02 15:04:05.000000 +0000"},
+		{"@1136214245 +1 day", "2006-01-03 15:04:05.000000 +0000"},
+		{"@-86400", "1969-12-31 00:00:00.000000 +0000"},
+
+		// timezones
+		{"2016-01-02 EST", "2016-01-02 00:00:00.000000 -0500"},
+		{"2016-01-02 15:04 (PDT)", "2016-01-02 15:04:00.000000 -0700"},
+		{"2016-01-02 15:04 GMT+5:30", "2016-01-02 15:04:00.000000 +0530"},
+		{"2016-01-02 15:04 Europe/Amsterdam", "2016-01-02 15:04:00.000000 +0100"},
+		{"2016-07-02 15:04 America/New_York", "2016-07-02 15:04:00.000000 -0400"},
+	}
+
+	for _, c := range cases {
+		tm, err := ParseStrToTime(c.str, base)
+		equal(t, nil, err)
+		equal(t, c.expected, tm.Format(layout))
+	}
+
+	// the result stays in the location of base
+	jakarta := time.FixedZone("WIB", 7*3600)
+	tm, _ := ParseStrToTime("tomorrow", base.In(jakarta))
+	equal(t, "2016-01-07 00:00:00.000000 +0700", tm.Format(layout))
+
+	// hours are elapsed time across DST changes
+	amsterdam, err := time.LoadLocation("Europe/Amsterdam")
+	equal(t, nil, err)
+	tm, _ = ParseStrToTime("+1 hour", time.Date(2021, 3, 28, 1, 30, 0, 0, amsterdam))
+	equal(t, "2021-03-28 03:30:00.000000 +0200", tm.Format(layout))
+	tm, _ = ParseStrToTime("+1 day", time.Date(2021, 3, 27, 12, 0, 0, 0, amsterdam))
+	equal(t, "2021-03-28 12:00:00.000000 +0200", tm.Format(layout))
+
+	for _, str := range []string{"", " \t ", "foo", "5", "2016-01-02 2016-01-03", "15:04 16:00", "+1 lightyear"} {
+		_, err := ParseStrToTime(str, base)
+		unequal(t, nil, err)
+	}
+
+	ts, err := StrToTimeFrom("+1 day", 1524799394)
+	equal(t, nil, err)
+	equal(t, int64(1524885794), ts)
+	ts, _ = StrToTimeFrom("2018-04-27 03:23:14 UTC", 0)
+	equal(t, int64(1524799394), ts)
+}
@@ -0,0 +1,251 @@
+package utils
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Return formats of DateSunrise and DateSunset
+const (
+	SunFuncsRetTimestamp = iota
+	SunFuncsRetString
+	SunFuncsRetDouble
+)
+
+// Sun altitudes, in degrees, of the events of SunInfo
+// The sunrise altitude accounts for refraction and the radius of the sun's disc.
+const (
+	SunriseAltitude              = -50.0 / 60
+	CivilTwilightAltitude        = -6.0
+	NauticalTwilightAltitude     = -12.0
+	AstronomicalTwilightAltitude = -18.0
+	SunFuncsDefaultZenith        = 90 - SunriseAltitude
+)
+
+// SunPeriod — When the sun is above an altitude during a day
+// Rise and Set are zero when the sun does not cross the altitude that day: AlwaysUp then tells
+// whether it stays above it, as in a polar day, or below it, as in a polar night.
+type SunPeriod struct {
+	Rise, Set time.Time
+	AlwaysUp  bool
+}
+
+// Crosses — Report whether the sun crosses the altitude that day
+func (p SunPeriod) Crosses() bool {
+	return !p.Rise.IsZero()
+}
+
+// SunTimes — Solar noon, sunrise, sunset and twilights of a day at a place
+// Sunrise.Rise is the sunrise, Civil.Rise the beginning of civil twilight, Civil.Set its end, and so on.
+type SunTimes struct {
+	Transit      time.Time
+	Sunrise      SunPeriod
+	Civil        SunPeriod
+	Nautical     SunPeriod
+	Astronomical SunPeriod
+}
+
+// SunInfo — Compute the sun events of the day of t, in t's location, at a latitude and longitude
+// It uses the NOAA solar equations, accurate to about a minute between latitudes 72°N and 72°S.
+// Latitudes are positive north of the equator and longitudes positive east of Greenwich.
+// The times are in t's location.
+// SunInfo(time.Date(2018, 6, 21, 0, 0, 0, 0, london), 51.5074, -0.1278).Sunrise.Rise // 04:43 BST
+func SunInfo(t time.Time, latitude, longitude float64) *SunTimes {
+	jd := sunDayJulian(t, longitude)
+	return &SunTimes{
+		Transit:      julianToTime(jd+sunTransit(jd, longitude)/1440, t.Location()),
+		Sunrise:      sunPeriod(jd, latitude, longitude, SunriseAltitude, t.Location()),
+		Civil:        sunPeriod(jd, latitude, longitude, CivilTwilightAltitude, t.Location()),
+		Nautical:     sunPeriod(jd, latitude, longitude, NauticalTwilightAltitude, t.Location()),
+		Astronomical: sunPeriod(jd, latitude, longitude, AstronomicalTwilightAltitude, t.Location()),
+	}
+}
+
+// DateSunInfo — Returns an array with information about sunset/sunrise and twilight begin/end
+// The day is the one of timestamp in the package default timezone. The values are Unix timestamps
+// as int64, or true when the sun stays above the altitude all day and false when it stays below.
+// DateSunInfo(1529539200, 51.5074, -0.1278)["sunrise"] == int64(1529552584)
+func DateSunInfo(timestamp int64, latitude, longitude float64) map[string]interface{} {
+	info := SunInfo(time.Unix(timestamp, 0).In(getLocation()), latitude, longitude)
+	unix := func(p SunPeriod, rise bool) interface{} {
+		switch {
+		case !p.Crosses():
+			return p.AlwaysUp
+		case rise:
+			return p.Rise.Unix()
+		}
+		return p.Set.Unix()
+	}
+
+	return map[string]interface{}{
+		"sunrise":                     unix(info.Sunrise, true),
+		"sunset":                      unix(info.Sunrise, false),
+		"transit":                     info.Transit.Unix(),
+		"civil_twilight_begin":        unix(info.Civil, true),
+		"civil_twilight_end":          unix(info.Civil, false),
+		"nautical_twilight_begin":     unix(info.Nautical, true),
+		"nautical_twilight_end":       unix(info.Nautical, false),
+		"astronomical_twilight_begin": unix(info.Astronomical, true),
+		"astronomical_twilight_end":   unix(info.Astronomical, false),
+	}
+}
+
+// DateSunrise — Returns time of sunrise for a given day and location
+// returnFormat is SunFuncsRetTimestamp (int64), SunFuncsRetString ("HH:MM") or SunFuncsRetDouble
+// (float64 hours). zenith is usually SunFuncsDefaultZenith and utcOffset, in hours, shifts the
+// string and double results. It returns false when the sun does not rise that day.
+// DateSunrise(1529539200, SunFuncsRetString, 51.5074, -0.1278, SunFuncsDefaultZenith, 1) == "04:43"
+func DateSunrise(timestamp int64, returnFormat int, latitude, longitude, zenith, utcOffset float64) interface{} {
+	return sunFunc(timestamp, returnFormat, latitude, longitude, zenith, utcOffset, true)
+}
+
+// DateSunset — Returns time of sunset for a given day and location
+// The arguments and results are those of DateSunrise.
+func DateSunset(timestamp int64, returnFormat int, latitude, longitude, zenith, utcOffset float64) interface{} {
+	return sunFunc(timestamp, returnFormat, latitude, longitude, zenith, utcOffset, false)
+}
+
+func sunFunc(timestamp int64, returnFormat int, latitude, longitude, zenith, utcOffset float64, rise bool) interface{} {
+	if returnFormat < SunFuncsRetTimestamp || returnFormat > SunFuncsRetDouble {
+		panic("returnFormat: must be SunFuncsRetTimestamp, SunFuncsRetString or SunFuncsRetDouble")
+	}
+
+	t := time.Unix(timestamp, 0).In(getLocation())
+	p := sunPeriod(sunDayJulian(t, longitude), latitude, longitude, 90-zenith, time.UTC)
+	if !p.Crosses() {
+		return false
+	}
+
+	event := p.Set
+	if rise {
+		event = p.Rise
+	}
+	if returnFormat == SunFuncsRetTimestamp {
+		return event.Unix()
+	}
+
+	hours := math.Mod(float64(event.Hour())+float64(event.Minute())/60+float64(event.Second())/3600+utcOffset, 24)
+	if hours < 0 {
+		hours += 24
+	}
+	if returnFormat == SunFuncsRetDouble {
+		return hours
+	}
+	minutes := int(hours*60) % 1440
+	return fmt.Sprintf("%02d:%02d", minutes/60, minutes%60)
+}
+
+// sunDayJulian returns the Julian day at 0h UT of the day whose solar noon at longitude is nearest
+// to noon of t's day in t's location
+func sunDayJulian(t time.Time, longitude float64) float64 {
+	y, m, d := t.Date()
+	jd := float64(GregorianToJd(int(m), d, y)) - 0.5
+	noon := timeToJulian(time.Date(y, m, d, 12, 0, 0, 0, t.Location()))
+	return jd + math.Round(noon-(jd+(720-4*longitude)/1440))
+}
+
+// sunPeriod computes when the sun crosses altitude on the day starting at jd, refining each event
+// with the sun's position at its first estimate
+func sunPeriod(jd, latitude, longitude, altitude float64, loc *time.Location) SunPeriod {
+	var p SunPeriod
+	for _, rise := range []bool{true, false} {
+		minutes, state := sunEvent(jd, jd+0.5-longitude/360, latitude, longitude, altitude, rise)
+		if state == 0 {
+			minutes, state = sunEvent(jd, jd+minutes/1440, latitude, longitude, altitude, rise)
+		}
+		if state != 0 {
+			return SunPeriod{AlwaysUp: state > 0}
+		}
+
+		if rise {
+			p.Rise = julianToTime(jd+minutes/1440, loc)
+		} else {
+			p.Set = julianToTime(jd+minutes/1440, loc)
+		}
+	}
+	return p
+}
+
+// sunEvent returns the minutes after the 0h UT of jd when the sun crosses altitude, computed with
+// the sun's position at the Julian day at. The state is 1 when the sun stays above the altitude,
+// -1 when it stays below and 0 when it crosses it.
+func sunEvent(jd, at, latitude, longitude, altitude float64, rise bool) (float64, int) {
+	t := julianCentury(at)
+	lat, decl := degToRad(latitude), degToRad(sunDeclination(t))
+
+	cosHourAngle := (math.Sin(degToRad(altitude)) - math.Sin(lat)*math.Sin(decl)) / (math.Cos(lat) * math.Cos(decl))
+	switch {
+	case cosHourAngle > 1:
+		return 0, -1
+	case cosHourAngle < -1:
+		return 0, 1
+	}
+
+	hourAngle := radToDeg(math.Acos(cosHourAngle))
+	if !rise {
+		hourAngle = -hourAngle
+	}
+	return 720 - 4*(longitude+hourAngle) - equationOfTime(t), 0
+}
+
+// sunTransit returns the minutes after 0h UT of jd of solar noon at longitude
+func sunTransit(jd, longitude float64) float64 {
+	noon := 720 - 4*longitude - equationOfTime(julianCentury(jd+0.5-longitude/360))
+	return 720 - 4*longitude - equationOfTime(julianCentury(jd+noon/1440))
+}
+
+// julianCentury returns the Julian centuries since J2000.0
+func julianCentury(jd float64) float64 {
+	return (jd - 2451545.0) / 36525
+}
+
+// sunGeometry returns the sun's geometric mean longitude and anomaly, the eccentricity of the
+// earth's orbit and the corrected obliquity of the ecliptic, in degrees
+func sunGeometry(t float64) (meanLong, meanAnomaly, eccentricity, obliquity float64) {
+	meanLong = math.Mod(280.46646+t*(36000.76983+t*0.0003032), 360)
+	meanAnomaly = 357.52911 + t*(35999.05029-0.0001537*t)
+	eccentricity = 0.016708634 - t*(0.000042037+0.0000001267*t)
+
+	seconds := 21.448 - t*(46.8150+t*(0.00059-t*0.001813))
+	omega := degToRad(125.04 - 1934.136*t)
+	obliquity = 23 + (26+seconds/60)/60 + 0.00256*math.Cos(omega)
+	return
+}
+
+// sunDeclination returns the sun's apparent declination in degrees
+func sunDeclination(t float64) float64 {
+	meanLong, meanAnomaly, _, obliquity := sunGeometry(t)
+	m := degToRad(meanAnomaly)
+	center := math.Sin(m)*(1.914602-t*(0.004817+0.000014*t)) + math.Sin(2*m)*(0.019993-0.000101*t) + math.Sin(3*m)*0.000289
+	apparentLong := meanLong + center - 0.00569 - 0.00478*math.Sin(degToRad(125.04-1934.136*t))
+	return radToDeg(math.Asin(math.Sin(degToRad(obliquity)) * math.Sin(degToRad(apparentLong))))
+}
+
+// equationOfTime returns the difference between true and mean solar time in minutes
+func equationOfTime(t float64) float64 {
+	meanLong, meanAnomaly, e, obliquity := sunGeometry(t)
+	y := math.Pow(math.Tan(degToRad(obliquity)/2), 2)
+	l0, m := degToRad(meanLong), degToRad(meanAnomaly)
+
+	eot := y*math.Sin(2*l0) - 2*e*math.Sin(m) + 4*e*y*math.Sin(m)*math.Cos(2*l0) -
+		0.5*y*y*math.Sin(4*l0) - 1.25*e*e*math.Sin(2*m)
+	return radToDeg(eot) * 4
+}
+
+func timeToJulian(t time.Time) float64 {
+	return float64(t.UnixNano())/1e9/86400 + 2440587.5
+}
+
+// julianToTime rounds to the second, the precision of the algorithm being far lower
+func julianToTime(jd float64, loc *time.Location) time.Time {
+	return time.Unix(int64(math.Round((jd-2440587.5)*86400)), 0).In(loc)
+}
+
+func degToRad(deg float64) float64 {
+	return deg * math.Pi / 180
+}
+
+func radToDeg(rad float64) float64 {
+	return rad * 180 / math.Pi
+}
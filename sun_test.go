@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSun(t *testing.T) {
+	london, _ := time.LoadLocation("Europe/London")
+	newYork, _ := time.LoadLocation("America/New_York")
+	sydney, _ := time.LoadLocation("Australia/Sydney")
+	oslo, _ := time.LoadLocation("Europe/Oslo")
+	kiritimati, _ := time.LoadLocation("Pacific/Kiritimati")
+
+	// almanac values, published to the minute
+	near := func(expected string, actual time.Time) {
+		at, _ := time.ParseInLocation("2006-01-02 15:04", expected, actual.Location())
+		rangeValue(t, float64(at.Unix()-60), float64(at.Unix()+60), float64(actual.Unix()))
+	}
+
+	s := SunInfo(time.Date(2018, 6, 21, 0, 0, 0, 0, london), 51.5074, -0.1278)
+	near("2018-06-21 04:43", s.Sunrise.Rise)
+	near("2018-06-21 21:21", s.Sunrise.Set)
+	near("2018-06-21 13:02", s.Transit)
+	near("2018-06-21 03:55", s.Civil.Rise)
+	near("2018-06-21 22:09", s.Civil.Set)
+	equal(t, false, s.Astronomical.Crosses())
+	equal(t, true, s.Astronomical.AlwaysUp)
+	equal(t, london, s.Sunrise.Rise.Location())
+
+	s = SunInfo(time.Date(2018, 6, 21, 15, 0, 0, 0, newYork), 40.7128, -74.0060)
+	near("2018-06-21 05:25", s.Sunrise.Rise)
+	near("2018-06-21 20:31", s.Sunrise.Set)
+	near("2018-06-21 04:52", s.Civil.Rise)
+	near("2018-06-21 21:04", s.Civil.Set)
+
+	s = SunInfo(time.Date(2018, 12, 21, 0, 0, 0, 0, sydney), -33.8688, 151.2093)
+	near("2018-12-21 05:41", s.Sunrise.Rise)
+	near("2018-12-21 20:05", s.Sunrise.Set)
+
+	// the day is the local one, even far from the meridian of the timezone
+	s = SunInfo(time.Date(2018, 3, 20, 0, 0, 0, 0, kiritimati), 1.87, -157.4)
+	equal(t, 20, s.Sunrise.Rise.Day())
+	equal(t, 20, s.Sunrise.Set.Day())
+
+	// polar night and polar day in Tromsø
+	s = SunInfo(time.Date(2018, 12, 21, 0, 0, 0, 0, oslo), 69.6492, 18.9553)
+	equal(t, SunPeriod{}, s.Sunrise)
+	equal(t, true, s.Civil.Crosses())
+	near("2018-12-21 11:42", s.Transit)
+	s = SunInfo(time.Date(2018, 6, 21, 0, 0, 0, 0, oslo), 69.6492, 18.9553)
+	equal(t, SunPeriod{AlwaysUp: true}, s.Sunrise)
+	equal(t, SunPeriod{AlwaysUp: true}, s.Nautical)
+
+	defer SetLocation(SetLocation(time.UTC))
+	day := time.Date(2018, 6, 21, 0, 0, 0, 0, time.UTC).Unix()
+	info := DateSunInfo(day, 51.5074, -0.1278)
+	equal(t, 9, len(info))
+	equal(t, SunInfo(time.Unix(day, 0).UTC(), 51.5074, -0.1278).Sunrise.Rise.Unix(), info["sunrise"])
+	equal(t, true, info["astronomical_twilight_begin"])
+	equal(t, true, info["astronomical_twilight_end"])
+	info = DateSunInfo(time.Date(2018, 12, 21, 0, 0, 0, 0, time.UTC).Unix(), 69.6492, 18.9553)
+	equal(t, false, info["sunrise"])
+	equal(t, false, info["sunset"])
+	gt(t, float64(info["civil_twilight_end"].(int64)), float64(info["civil_twilight_begin"].(int64)))
+
+	equal(t, "04:43", DateSunrise(day, SunFuncsRetString, 51.5074, -0.1278, SunFuncsDefaultZenith, 1))
+	equal(t, "21:21", DateSunset(day, SunFuncsRetString, 51.5074, -0.1278, SunFuncsDefaultZenith, 1))
+	equal(t, false, DateSunrise(day, SunFuncsRetTimestamp, 69.6492, 18.9553, SunFuncsDefaultZenith, 0))
+	equal(t, false, DateSunrise(time.Date(2018, 12, 21, 0, 0, 0, 0, time.UTC).Unix(), SunFuncsRetTimestamp, 69.6492, 18.9553, SunFuncsDefaultZenith, 0))
+	rangeValue(t, 20.34, 20.36, DateSunset(day, SunFuncsRetDouble, 51.5074, -0.1278, SunFuncsDefaultZenith, 0).(float64))
+	equal(t, info["civil_twilight_begin"], DateSunrise(time.Date(2018, 12, 21, 0, 0, 0, 0, time.UTC).Unix(), SunFuncsRetTimestamp, 69.6492, 18.9553, 96, 0))
+}
@@ -1,7 +1,12 @@
 package utils
 
 import (
+	"cmp"
+	"fmt"
+	"math"
 	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -87,3 +92,270 @@ func IsNumeric(val interface{}) bool {
 
 	return false
 }
+
+var numericStringRegexp = regexp.MustCompile(`^[ \t\n\r\v\f]*[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?[ \t\n\r\v\f]*$`)
+
+// phpNumber converts a number or a PHP numeric string to float64
+func phpNumber(val interface{}) (float64, bool) {
+	switch v := val.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		if !numericStringRegexp.MatchString(v) {
+			return 0, false
+		}
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+
+	return 0, false
+}
+
+var numericPrefixRegexp = regexp.MustCompile(`^[ \t\n\r\v\f]*[+-]?(\d+(\.\d*)?|\.\d+)([eE][+-]?\d+)?`)
+
+// phpFloat converts a value to float64 the way PHP's (float) cast does
+// Strings use their leading numeric part, so "12abc" is 12 and "abc" is 0.
+func phpFloat(val interface{}) float64 {
+	switch v := val.(type) {
+	case nil:
+		return 0
+	case bool:
+		if v {
+			return 1
+		}
+		return 0
+	case string:
+		f, _ := strconv.ParseFloat(strings.TrimSpace(numericPrefixRegexp.FindString(v)), 64)
+		return f
+	}
+
+	f, _ := phpNumber(val)
+	return f
+}
+
+// phpInt converts a Go integer to int64
+func phpInt(val interface{}) (int64, bool) {
+	switch v := val.(type) {
+	case int:
+		return int64(v), true
+	case int8:
+		return int64(v), true
+	case int16:
+		return int64(v), true
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case uint:
+		return int64(v), true
+	case uint8:
+		return int64(v), true
+	case uint16:
+		return int64(v), true
+	case uint32:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	}
+
+	return 0, false
+}
+
+// phpBool converts a value to boolean the way PHP does
+// "", "0", 0, 0.0, nil and empty slices or maps are false.
+func phpBool(val interface{}) bool {
+	switch v := val.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		return v != "" && v != "0"
+	}
+
+	if f, ok := phpNumber(val); ok {
+		return f != 0
+	}
+
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() > 0
+	}
+
+	return true
+}
+
+// phpString converts a value to string the way PHP does
+// true is "1", false and nil are "", floats use 14 significant digits.
+func phpString(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return ""
+	case bool:
+		if v {
+			return "1"
+		}
+		return ""
+	case string:
+		return v
+	case float32:
+		return phpFloatString(float64(v))
+	case float64:
+		return phpFloatString(v)
+	}
+
+	if i, ok := phpInt(val); ok {
+		if u, ok := val.(uint64); ok {
+			return strconv.FormatUint(u, 10)
+		}
+		return strconv.FormatInt(i, 10)
+	}
+
+	switch reflect.ValueOf(val).Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return "Array"
+	}
+
+	return fmt.Sprint(val)
+}
+
+// phpFloatString formats a float with PHP's default precision of 14
+func phpFloatString(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "NAN"
+	case math.IsInf(f, 1):
+		return "INF"
+	case math.IsInf(f, -1):
+		return "-INF"
+	case f == 0:
+		if math.Signbit(f) {
+			return "-0"
+		}
+		return "0"
+	}
+
+	// d.ddddddddddddde±x
+	s := strconv.FormatFloat(f, 'e', 13, 64)
+	sign := ""
+	if s[0] == '-' {
+		sign, s = "-", s[1:]
+	}
+
+	pos := strings.IndexByte(s, 'e')
+	exp, _ := strconv.Atoi(s[pos+1:])
+	digits := strings.TrimRight(s[:1]+s[2:pos], "0")
+	decpt := exp + 1
+
+	if decpt < -3 || decpt > 14 {
+		mantissa := digits[:1] + "." + digits[1:]
+		if len(digits) == 1 {
+			mantissa += "0"
+		}
+		expSign := "+"
+		if exp < 0 {
+			expSign, exp = "-", -exp
+		}
+		return sign + mantissa + "E" + expSign + strconv.Itoa(exp)
+	}
+
+	if decpt <= 0 {
+		return sign + "0." + strings.Repeat("0", -decpt) + digits
+	}
+	if decpt >= len(digits) {
+		return sign + digits + strings.Repeat("0", decpt-len(digits))
+	}
+
+	return sign + digits[:decpt] + "." + digits[decpt:]
+}
+
+// phpCompare compares two values with PHP 8 loose comparison rules (the <=> operator)
+func phpCompare(a, b interface{}) int {
+	as, aIsStr := a.(string)
+	bs, bIsStr := b.(string)
+
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil && bIsStr:
+		return strings.Compare("", bs)
+	case b == nil && aIsStr:
+		return strings.Compare(as, "")
+	}
+
+	_, aIsBool := a.(bool)
+	_, bIsBool := b.(bool)
+	if a == nil || b == nil || aIsBool || bIsBool {
+		ab, bb := phpBool(a), phpBool(b)
+		switch {
+		case ab == bb:
+			return 0
+		case bb:
+			return -1
+		}
+		return 1
+	}
+
+	ai, aIsInt := phpInt(a)
+	bi, bIsInt := phpInt(b)
+	if aIsInt && bIsInt {
+		return cmp.Compare(ai, bi)
+	}
+
+	af, aIsNum := phpNumber(a)
+	bf, bIsNum := phpNumber(b)
+	if aIsNum && bIsNum {
+		return cmp.Compare(af, bf)
+	}
+
+	if aIsStr || bIsStr {
+		return strings.Compare(phpString(a), phpString(b))
+	}
+
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	if (av.Kind() == reflect.Slice || av.Kind() == reflect.Array) && (bv.Kind() == reflect.Slice || bv.Kind() == reflect.Array) {
+		if c := cmp.Compare(av.Len(), bv.Len()); c != 0 {
+			return c
+		}
+		for i := 0; i < av.Len(); i++ {
+			if c := phpCompare(av.Index(i).Interface(), bv.Index(i).Interface()); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return 0
+	}
+
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+// phpEqual reports whether a == b under PHP 8 loose comparison rules
+func phpEqual(a, b interface{}) bool {
+	return phpCompare(a, b) == 0
+}